@@ -18,56 +18,12 @@ package main
 
 import (
 	"flag"
-	"fmt"
-	"github.com/nachocano/gsuite-source/pkg/adapter/calendar"
-	"go.uber.org/zap"
-	"log"
-	"net/http"
-	"os"
-)
 
-const (
-	// Environment variable containing the HTTP port
-	envPort = "PORT"
-	// Environment variable containing the sink
-	envSink = "SINK"
+	"github.com/nachocano/gsuite-source/pkg/adapter"
+	"github.com/nachocano/gsuite-source/pkg/gsuite/calendar"
 )
 
 func main() {
 	flag.Parse()
-
-	log.Print("Starting Calendar Adapter...")
-
-	sink := os.Getenv(envSink)
-	if sink == "" {
-		log.Fatal("No sink given")
-	}
-	log.Printf("Sink %s", sink)
-
-	port := os.Getenv(envPort)
-	if port == "" {
-		port = "8080"
-	}
-	log.Printf("Port %s", port)
-
-	ra, err := calendar.New(sink)
-	if err != nil {
-		log.Fatalf("Failed to create Calendar Adapter: %v", zap.Error(err))
-	}
-
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		event, err := ra.ParseEvent(r)
-		if err != nil {
-			log.Printf("Error parsing event: %v", err)
-			return
-		}
-		ra.HandleEvent(event, r.Header)
-	})
-
-	addr := fmt.Sprintf(":%s", port)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Failed to start Calendar Adapter: %v", zap.Error(err))
-	}
-
-	log.Print("Started Calendar Adapter")
+	adapter.Main(calendar.Kind{})
 }
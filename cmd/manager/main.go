@@ -17,25 +17,38 @@ limitations under the License.
 package main
 
 import (
-	"github.com/knative/pkg/logging/logkey"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 	"log"
+	"os"
 
+	"github.com/knative/pkg/configmap"
+	"github.com/knative/pkg/logging"
+	"github.com/knative/pkg/logging/logkey"
 	"github.com/nachocano/gsuite-source/pkg/apis"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
 	"github.com/nachocano/gsuite-source/pkg/controller"
+	"github.com/nachocano/gsuite-source/pkg/reconciler/resources"
+	"github.com/nachocano/gsuite-source/pkg/webhook"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 )
 
+const (
+	// systemNamespaceEnvVar is the environment variable with the namespace the controller runs in.
+	systemNamespaceEnvVar = "SYSTEM_NAMESPACE"
+	// metricsAddr is the address the controller exposes its Prometheus metrics on.
+	metricsAddr = ":9090"
+)
+
 func main() {
-	logCfg := zap.NewProductionConfig()
-	logCfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	logger, err := logCfg.Build()
-	logger = logger.With(zap.String(logkey.ControllerType, "gsuite-controller-manager"))
-	if err != nil {
-		log.Fatal(err)
+	namespace := os.Getenv(systemNamespaceEnvVar)
+	if namespace == "" {
+		log.Fatalf("Missing %s environment variable", systemNamespaceEnvVar)
 	}
 
 	// Get a config to talk to the apiserver.
@@ -43,9 +56,45 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Build the logger from the config-logging ConfigMap, and update its level when the ConfigMap changes.
+	// If the ConfigMap does not exist, the controller logs with the default config, as the receive adapters do.
+	loggingConfigMap, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(resources.LoggingConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		log.Printf("ConfigMap %q not found, using the default logging config", resources.LoggingConfigMapName)
+		loggingConfigMap = nil
+	} else if err != nil {
+		log.Fatalf("Failed to get the %s ConfigMap: %v", resources.LoggingConfigMapName, err)
+	}
+	var loggingData map[string]string
+	if loggingConfigMap != nil {
+		loggingData = loggingConfigMap.Data
+	}
+	loggingConfig, err := logging.NewConfigFromMap(loggingData, resources.LoggingComponentController)
+	if err != nil {
+		log.Fatalf("Failed to parse the %s ConfigMap: %v", resources.LoggingConfigMapName, err)
+	}
+	logger, atomicLevel := logging.NewLoggerFromConfig(loggingConfig, resources.LoggingComponentController)
+	logger = logger.With(zap.String(logkey.ControllerType, "gsuite-controller-manager"))
+	defer logger.Sync()
+
+	stopCh := signals.SetupSignalHandler()
+	// The watcher fails to start if the ConfigMap does not exist, so the level of the default config is not updated.
+	if loggingConfigMap != nil {
+		cmw := configmap.NewInformedWatcher(kubeClient, namespace)
+		cmw.Watch(resources.LoggingConfigMapName, logging.UpdateLevelFromConfigMap(logger, atomicLevel,
+			resources.LoggingComponentController, resources.LoggingComponentController))
+		if err := cmw.Start(stopCh); err != nil {
+			logger.Fatalw("Failed to start the ConfigMap watcher", zap.Error(err))
+		}
+	}
 
 	// Create a new Cmd to provide shared dependencies and start components
-	mgr, err := manager.New(cfg, manager.Options{})
+	mgr, err := manager.New(cfg, manager.Options{MetricsBindAddress: metricsAddr})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -58,12 +107,36 @@ func main() {
 	}
 
 	// Setup GSuite Controller.
-	if err := controller.AddToManager(mgr, logger.Sugar()); err != nil {
+	if err := controller.AddToManager(mgr, logger); err != nil {
+		log.Fatal(err)
+	}
+
+	// Setup the admission webhook that defaults and validates the sources.
+	ac := &webhook.AdmissionController{
+		Client: kubeClient,
+		Options: webhook.Options{
+			ServiceName: "gsuite-controller",
+			Namespace:   namespace,
+			Port:        8443,
+			SecretName:  "gsuite-webhook-certs",
+			WebhookName: "webhook.sources.nachocano.org",
+		},
+		Handlers: map[schema.GroupVersionKind]webhook.GenericCRD{
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("CalendarSource"):  &sourcesv1alpha1.CalendarSource{},
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("DirectorySource"): &sourcesv1alpha1.DirectorySource{},
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("DriveSource"):     &sourcesv1alpha1.DriveSource{},
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("GmailSource"):     &sourcesv1alpha1.GmailSource{},
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("ReportsSource"):   &sourcesv1alpha1.ReportsSource{},
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("SheetsSource"):    &sourcesv1alpha1.SheetsSource{},
+		},
+		Logger: logger.With(zap.String(logkey.ControllerType, "webhook")),
+	}
+	if err := mgr.Add(ac); err != nil {
 		log.Fatal(err)
 	}
 
 	log.Printf("Starting GSuite Controller")
 
 	// Start the Cmd
-	log.Fatal(mgr.Start(signals.SetupSignalHandler()))
+	log.Fatal(mgr.Start(stopCh))
 }
@@ -0,0 +1,521 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package adapter implements a receive adapter for any G Suite source kind.
+package adapter
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knative/pkg/logging"
+	"github.com/nachocano/gsuite-source/pkg/adapter/metrics"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"go.uber.org/zap"
+)
+
+const (
+	headerResourceURI   = "Goog-Resource-URI"
+	headerChannelId     = "Goog-Channel-ID"
+	headerChannelToken  = "Goog-Channel-Token"
+	headerResourceState = "Goog-Resource-State"
+	headerChanged       = "Goog-Changed"
+
+	// tokenFilePrefix is the prefix of the files of the channel tokens secret with the tokens.
+	tokenFilePrefix = "token-"
+	// channelsFile is the file of the channel tokens secret with the JSON object of the channels
+	// of the watched resources, by gsuite.CursorKey.
+	channelsFile = "channels"
+
+	// maxBodyBytes is the maximum size of the notification payloads we read.
+	maxBodyBytes = 1 << 20
+
+	// The keys of the fields of the structured logs.
+	logKeySource    = "source"
+	logKeyNamespace = "namespace"
+	logKeyUser      = "user"
+	logKeyResource  = "resource"
+	logKeyChannelId = "channelId"
+	logKeyEventId   = "eventId"
+)
+
+// Options configure an adapter.
+type Options struct {
+	// Sink is the URI the CloudEvents are sent to.
+	Sink string
+	// DeadLetterSink is the URI the CloudEvents that could not be delivered to the sink are sent to, if any.
+	DeadLetterSink string
+	// Retries is the number of times a failed delivery is retried.
+	Retries int
+	// BackoffDelay is the delay before the first retry of a failed delivery, doubled on every following retry.
+	BackoffDelay time.Duration
+	// SendTimeout is how long a delivery attempt waits for the sink to respond. It defaults to 30 seconds.
+	SendTimeout time.Duration
+	// CloudEvents is the spec version and HTTP content mode of the CloudEvents sent to the sink.
+	CloudEvents sourcesv1alpha1.CloudEventsSpec
+	// CredentialsFile is the path to the service account credentials.
+	CredentialsFile string
+	// TokensDir is the directory where the channel tokens secret is mounted.
+	TokensDir string
+	// PollInterval is how often all the resources are polled in poll mode, or zero.
+	PollInterval time.Duration
+	// DedupWindowSize is the number of sent events remembered to suppress duplicates.
+	// It defaults to 10000.
+	DedupWindowSize int
+	// Watches are the resources of the users to watch.
+	Watches []gsuite.Watch
+	// Cursors stores the cursors to start reading the changes of the watched resources from.
+	Cursors CursorStore
+	// Logger is the logger of the adapter. It defaults to the Knative fallback logger.
+	Logger *zap.SugaredLogger
+}
+
+type Adapter struct {
+	kind   gsuite.Kind
+	logger *zap.SugaredLogger
+
+	// tokensDir is the directory where the channel tokens secret is mounted. The tokens and the channels
+	// are read on every notification, as they change when the channels are renewed.
+	tokensDir string
+
+	// pollInterval is how often all the resources are polled in poll mode, or zero.
+	pollInterval time.Duration
+
+	sender sender
+	// deadLetterSender sends the events that could not be delivered to the sink, if any.
+	deadLetterSender sender
+	retries          int
+	backoffDelay     time.Duration
+	// dedup remembers the last sent events, so that the redelivered ones are not sent again.
+	dedup *dedupWindow
+	// cursors saves the cursors of the watched resources as they advance.
+	cursors CursorStore
+
+	// watches are the watched resources, by user email address and resource.
+	watches map[watchKey]*watch
+}
+
+// pushEnvelope is the body of the Pub/Sub push requests.
+type pushEnvelope struct {
+	Message struct {
+		// Data is base64 encoded in the request.
+		Data       []byte            `json:"data"`
+		Attributes map[string]string `json:"attributes"`
+		MessageId  string            `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// StatusError is an error parsing a push notification, with the HTTP status code of the response.
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func statusErrorf(code int, format string, a ...interface{}) *StatusError {
+	return &StatusError{Code: code, Err: fmt.Errorf(format, a...)}
+}
+
+// StatusCode returns the HTTP status code of the response to a notification that could not be parsed.
+func StatusCode(err error) int {
+	if e, ok := err.(*StatusError); ok {
+		return e.Code
+	}
+	return http.StatusBadRequest
+}
+
+type watchKey struct {
+	email    string
+	resource string
+}
+
+// watch is a watched resource of a user.
+type watch struct {
+	gsClient gsuite.Client
+
+	// cursorKey is the key of the cursor of the resource in the CursorStore, and of its channel
+	// in the channel tokens secret.
+	cursorKey string
+	// cursor is the position from where we read the changes of the resource on the next notification.
+	// It is guarded by cursorMu, as notifications can arrive concurrently.
+	cursor   string
+	cursorMu sync.Mutex
+}
+
+// New returns an adapter for the given resources of the given users,
+// starting to read their changes from the saved cursors. If the poll interval is not zero,
+// the changes of all the resources are polled, instead of read on every push notification.
+func New(kind gsuite.Kind, opts Options) (*Adapter, error) {
+	a := new(Adapter)
+	var err error
+	a.kind = kind
+	a.logger = opts.Logger
+	if a.logger == nil {
+		a.logger = logging.FromContext(context.Background())
+	}
+	a.tokensDir = opts.TokensDir
+	a.pollInterval = opts.PollInterval
+	sendTimeout := opts.SendTimeout
+	if sendTimeout <= 0 {
+		sendTimeout = defaultSendTimeout
+	}
+	a.sender, err = newSender(opts.Sink, opts.CloudEvents, sendTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DeadLetterSink != "" {
+		a.deadLetterSender, err = newSender(opts.DeadLetterSink, opts.CloudEvents, sendTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	a.retries = opts.Retries
+	a.backoffDelay = opts.BackoffDelay
+	dedupWindowSize := opts.DedupWindowSize
+	if dedupWindowSize <= 0 {
+		dedupWindowSize = defaultDedupWindowSize
+	}
+	a.dedup = newDedupWindow(dedupWindowSize)
+	// Doing this as there is no way to impersonate a particular user
+	// using the GOOGLE_APPLICATION_CREDENTIALS env variable.
+	credentials, err := ioutil.ReadFile(opts.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	a.cursors = opts.Cursors
+	cursors, err := a.cursors.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the cursors: %v", err)
+	}
+	// The resources of the same user share the client.
+	gsClients := make(map[string]gsuite.Client)
+	a.watches = make(map[watchKey]*watch, len(opts.Watches))
+	for _, w := range opts.Watches {
+		gsClient, ok := gsClients[w.EmailAddress]
+		if !ok {
+			gsClient, err = kind.NewClient(context.Background(), credentials, w.EmailAddress)
+			if err != nil {
+				return nil, err
+			}
+			gsClients[w.EmailAddress] = gsClient
+		}
+		cursorKey := gsuite.CursorKey(w.EmailAddress, w.Resource)
+		// The controller adds the cursors of the new resources before it adds them to the watches.
+		cursor, ok := cursors[cursorKey]
+		if !ok {
+			return nil, fmt.Errorf("no cursor for user %q resource %q", w.EmailAddress, w.Resource)
+		}
+		a.watches[watchKey{email: w.EmailAddress, resource: w.Resource}] = &watch{
+			gsClient:  gsClient,
+			cursorKey: cursorKey,
+			cursor:    cursor,
+		}
+	}
+	return a, nil
+}
+
+// ParseEvent parses a push notification. The errors are *StatusError, with the status code of the response.
+// The sync notification sent when a channel is created is returned as any other, with the sync resource state.
+func (a *Adapter) ParseEvent(r *http.Request) (*gsuite.Notification, error) {
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		_ = r.Body.Close()
+	}()
+
+	if r.Method != http.MethodPost {
+		return nil, statusErrorf(http.StatusMethodNotAllowed, "invalid HTTP Method %s", r.Method)
+	}
+
+	// The notifications delivered through Pub/Sub carry the channel token in the query.
+	if token := r.URL.Query().Get(gsuite.QueryChannelToken); token != "" && r.Header.Get("X-"+headerChannelToken) == "" {
+		return a.parsePubsubEvent(r, token)
+	}
+
+	token := r.Header.Get("X-" + headerChannelToken)
+	if token == "" {
+		return nil, statusErrorf(http.StatusUnauthorized, "missing X-%s header", headerChannelToken)
+	}
+	if err := a.verifyToken(token); err != nil {
+		return nil, err
+	}
+
+	key, err := a.watchKey(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	channelId := r.Header.Get("X-" + headerChannelId)
+	state := r.Header.Get("X-" + headerResourceState)
+	// The sync notification is sent right after the channel is created, usually before the receive adapter
+	// is updated with its ID. It does not carry any change, so it is acknowledged from any channel.
+	if !strings.EqualFold(gsuite.ResourceStateSync, state) {
+		if err := a.verifyChannel(key, channelId); err != nil {
+			return nil, err
+		}
+	}
+	// Most push notifications do not have payloads, and the actual changes are read by the G Suite client.
+	// Others, e.g., the Reports ones, carry the changes in their body.
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, statusErrorf(http.StatusBadRequest, "failed to read body: %v", err)
+	}
+
+	notification := &gsuite.Notification{
+		User:          key.email,
+		Resource:      key.resource,
+		ChannelId:     channelId,
+		MessageNumber: r.Header.Get("X-" + gsuite.HeaderMessageNumber),
+		ResourceId:    r.Header.Get("X-" + gsuite.HeaderResourceID),
+		ResourceURI:   r.Header.Get("X-" + headerResourceURI),
+		ResourceState: state,
+		Data:          data,
+	}
+	if changed := r.Header.Get("X-" + headerChanged); changed != "" {
+		notification.Changed = strings.Split(changed, ",")
+	}
+	return notification, nil
+}
+
+// parsePubsubEvent parses a Pub/Sub push envelope, whose message data is the payload of the notification.
+// The subscription of the envelope is the channel of the notification.
+func (a *Adapter) parsePubsubEvent(r *http.Request, token string) (*gsuite.Notification, error) {
+	if err := a.verifyToken(token); err != nil {
+		return nil, err
+	}
+	key, err := a.watchKey(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	var envelope pushEnvelope
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes)).Decode(&envelope); err != nil {
+		return nil, statusErrorf(http.StatusBadRequest, "invalid Pub/Sub push envelope: %v", err)
+	}
+	if err := a.verifyChannel(key, envelope.Subscription); err != nil {
+		return nil, err
+	}
+	return &gsuite.Notification{
+		User:          key.email,
+		Resource:      key.resource,
+		ChannelId:     envelope.Subscription,
+		ResourceId:    envelope.Message.MessageId,
+		ResourceState: "exists",
+		Data:          envelope.Message.Data,
+	}, nil
+}
+
+// watchKey returns the watched user and resource of a notification URL. The channels, and the Pub/Sub
+// push subscriptions, are created with the email address of the user and the resource as path.
+func (a *Adapter) watchKey(u *url.URL) (watchKey, error) {
+	key, err := parsePath(u)
+	if err != nil {
+		return watchKey{}, statusErrorf(http.StatusNotFound, "%v", err)
+	}
+	if _, ok := a.watches[key]; !ok {
+		return watchKey{}, statusErrorf(http.StatusNotFound, "unknown user %q resource %q", key.email, key.resource)
+	}
+	return key, nil
+}
+
+// verifyChannel checks that channelId is the channel the controller registered for the watched resource,
+// as listed in the channel tokens secret. The notifications of the stale channels, e.g., the ones replaced
+// on renewal, are rejected. The ones of a channel created before the mounted secret is refreshed are
+// rejected too, but no change is lost, as the changes are read from the cursor of the resource on the
+// next accepted notification.
+func (a *Adapter) verifyChannel(key watchKey, channelId string) error {
+	b, err := ioutil.ReadFile(filepath.Join(a.tokensDir, channelsFile))
+	if err != nil && !os.IsNotExist(err) {
+		return statusErrorf(http.StatusInternalServerError, "failed to read channels: %v", err)
+	}
+	channels := make(map[string]string)
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &channels); err != nil {
+			return statusErrorf(http.StatusInternalServerError, "invalid channels: %v", err)
+		}
+	}
+	want := channels[a.watches[key].cursorKey]
+	if channelId == "" || subtle.ConstantTimeCompare([]byte(channelId), []byte(want)) != 1 {
+		return statusErrorf(http.StatusForbidden, "unknown channel %q of user %q resource %q", channelId, key.email, key.resource)
+	}
+	return nil
+}
+
+// verifyToken checks that token is one of the channel tokens of the source.
+func (a *Adapter) verifyToken(token string) error {
+	files, err := ioutil.ReadDir(a.tokensDir)
+	if err != nil {
+		return statusErrorf(http.StatusInternalServerError, "failed to read channel tokens: %v", err)
+	}
+	for _, file := range files {
+		// Skip the channels, and the internal files of the secret volume.
+		if !strings.HasPrefix(file.Name(), tokenFilePrefix) {
+			continue
+		}
+		want, err := ioutil.ReadFile(filepath.Join(a.tokensDir, file.Name()))
+		if err != nil {
+			return statusErrorf(http.StatusInternalServerError, "failed to read channel token: %v", err)
+		}
+		if len(want) > 0 && subtle.ConstantTimeCompare([]byte(token), want) == 1 {
+			return nil
+		}
+	}
+	return statusErrorf(http.StatusForbidden, "token mismatch")
+}
+
+// ServeHTTP implements http.Handler. It acknowledges the push notifications whose events were delivered,
+// and responds with a 503 to the others, so that Google redelivers them. The deliveries are canceled
+// when the request is, e.g., when Google gives up waiting for the response.
+func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	notification, err := a.ParseEvent(r)
+	if err != nil {
+		a.logger.Warnw("Failed to parse the notification", zap.String(logKeyChannelId, r.Header.Get("X-"+headerChannelId)), zap.Error(err))
+		code := StatusCode(err)
+		if code == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", http.MethodPost)
+		}
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	metrics.NotificationsReceived.WithLabelValues(a.kind.Name(), strings.ToLower(notification.ResourceState)).Inc()
+	if err := a.HandleEvent(r.Context(), notification); err != nil {
+		// Google redelivers the notifications that are not acknowledged with a 2xx response.
+		http.Error(w, "failed to deliver the events", http.StatusServiceUnavailable)
+	}
+}
+
+// HandleEvent sends the events of the notification to the sink. It returns an error if any of them
+// could not be delivered, neither to the sink nor to the dead letter sink, before the context is done,
+// so that Google redelivers it.
+func (a *Adapter) HandleEvent(ctx context.Context, notification *gsuite.Notification) error {
+	logger := a.logger.With(
+		zap.String(logKeyUser, notification.User),
+		zap.String(logKeyResource, notification.Resource),
+		zap.String(logKeyChannelId, notification.ChannelId))
+	err := a.handleEvent(logging.WithLogger(ctx, logger), notification)
+	if err != nil {
+		logger.Errorw("Failed to handle the notification", zap.Error(err))
+	}
+	return err
+}
+
+func (a *Adapter) handleEvent(ctx context.Context, notification *gsuite.Notification) error {
+	logger := logging.FromContext(ctx)
+	if strings.EqualFold(gsuite.ResourceStateSync, notification.ResourceState) {
+		logger.Info("Sync message received")
+		metrics.SyncHandshakes.WithLabelValues(a.kind.Name()).Inc()
+		return nil
+	}
+	logger.Debugw("Notification received",
+		zap.String("resourceState", notification.ResourceState),
+		zap.String("resourceId", notification.ResourceId),
+		zap.String("resourceUri", notification.ResourceURI))
+
+	w := a.watches[watchKey{email: notification.User, resource: notification.Resource}]
+	w.cursorMu.Lock()
+	defer w.cursorMu.Unlock()
+
+	events, cursor, err := w.gsClient.Events(ctx, w.cursor, notification)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		key := dedupKey(event)
+		if a.dedup.Seen(key) {
+			logger.Debugw("Skipping duplicate event", zap.String(logKeyEventId, event.ID()))
+			continue
+		}
+		if err := a.deliver(ctx, event); err != nil {
+			// Do not advance the cursor, so that we retry on the next notification.
+			return fmt.Errorf("failed to send event %q: %v", event.ID(), err)
+		}
+		a.dedup.Add(key)
+		logger.Debugw("Event sent", zap.String(logKeyEventId, event.ID()), zap.String("eventType", event.Type()))
+	}
+	if cursor == w.cursor {
+		return nil
+	}
+	w.cursor = cursor
+	// The events were delivered, so we just log if we fail to save the cursor. It is saved again
+	// when it next advances, and only a restart before then reads the same changes again.
+	if err := a.cursors.Save(w.cursorKey, cursor); err != nil {
+		logger.Warnw("Failed to save the cursor", zap.Error(err))
+	}
+	return nil
+}
+
+// Poll reads the events of the polled resources on every poll interval, until the context is done.
+// In poll mode, all the resources are polled. The first poll happens right away.
+func (a *Adapter) Poll(ctx context.Context) {
+	for key, w := range a.watches {
+		interval := a.pollInterval
+		if poller, ok := w.gsClient.(gsuite.Poller); ok && interval == 0 {
+			interval = poller.PollInterval(key.resource)
+		}
+		if interval > 0 {
+			go a.poll(ctx, key, interval)
+		}
+	}
+}
+
+func (a *Adapter) poll(ctx context.Context, key watchKey, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// The error is logged, and we retry on the next poll.
+		_ = a.HandleEvent(ctx, &gsuite.Notification{
+			User:          key.email,
+			Resource:      key.resource,
+			ResourceState: gsuite.ResourceStatePoll,
+		})
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// parsePath returns the user email address and the resource of the path of a notification URL,
+// i.e., /<email address>/<resource>.
+func parsePath(u *url.URL) (watchKey, error) {
+	segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	if len(segments) != 2 {
+		return watchKey{}, fmt.Errorf("invalid path %q", u.Path)
+	}
+	email, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return watchKey{}, fmt.Errorf("invalid path %q: %v", u.Path, err)
+	}
+	resource, err := url.PathUnescape(segments[1])
+	if err != nil {
+		return watchKey{}, fmt.Errorf("invalid path %q: %v", u.Path, err)
+	}
+	return watchKey{email: email, resource: resource}, nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+)
+
+const (
+	testEmail     = "user@example.com"
+	testResource  = "primary"
+	testChannelId = "channel-1"
+	testToken     = "token-value"
+	testPath      = "/user%40example.com/primary"
+)
+
+// newTokensDir returns a directory with testToken, and testChannelId as the channel of testResource of testEmail,
+// as the channel tokens secret is mounted. It must be removed by the caller.
+func newTokensDir(t *testing.T) string {
+	t.Helper()
+	tokensDir, err := ioutil.TempDir("", "tokens")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	files := map[string]string{
+		"token-1":    testToken,
+		channelsFile: fmt.Sprintf(`{%q: %q}`, gsuite.CursorKey(testEmail, testResource), testChannelId),
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(tokensDir, name), []byte(data), 0600); err != nil {
+			os.RemoveAll(tokensDir)
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+	return tokensDir
+}
+
+// newTestAdapter returns an adapter watching testResource of testEmail.
+func newTestAdapter(tokensDir string) *Adapter {
+	return &Adapter{
+		tokensDir: tokensDir,
+		watches: map[watchKey]*watch{
+			{email: testEmail, resource: testResource}: {cursorKey: gsuite.CursorKey(testEmail, testResource)},
+		},
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	headers := func(token, channelId, state string) map[string]string {
+		h := map[string]string{"X-" + headerResourceState: state}
+		if token != "" {
+			h["X-"+headerChannelToken] = token
+		}
+		if channelId != "" {
+			h["X-"+headerChannelId] = channelId
+		}
+		return h
+	}
+	tokensDir := newTokensDir(t)
+	defer os.RemoveAll(tokensDir)
+	pubsubBody := `{"message": {"data": "e30=", "messageId": "1"}, "subscription": "` + testChannelId + `"}`
+
+	testCases := map[string]struct {
+		method   string
+		target   string
+		headers  map[string]string
+		body     string
+		wantCode int
+	}{
+		"notification": {
+			target:  testPath,
+			headers: headers(testToken, testChannelId, "exists"),
+		},
+		"sync notification from a new channel": {
+			target:  testPath,
+			headers: headers(testToken, "channel-2", gsuite.ResourceStateSync),
+		},
+		"wrong method": {
+			method:   http.MethodGet,
+			target:   testPath,
+			headers:  headers(testToken, testChannelId, "exists"),
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		"missing token": {
+			target:   testPath,
+			headers:  headers("", testChannelId, "exists"),
+			wantCode: http.StatusUnauthorized,
+		},
+		"bad token": {
+			target:   testPath,
+			headers:  headers("other-token", testChannelId, "exists"),
+			wantCode: http.StatusForbidden,
+		},
+		"channels as token": {
+			target:   testPath,
+			headers:  headers(fmt.Sprintf(`{%q: %q}`, gsuite.CursorKey(testEmail, testResource), testChannelId), testChannelId, "exists"),
+			wantCode: http.StatusForbidden,
+		},
+		"bad channel": {
+			target:   testPath,
+			headers:  headers(testToken, "channel-2", "exists"),
+			wantCode: http.StatusForbidden,
+		},
+		"missing channel": {
+			target:   testPath,
+			headers:  headers(testToken, "", "exists"),
+			wantCode: http.StatusForbidden,
+		},
+		"unknown resource": {
+			target:   "/user%40example.com/other",
+			headers:  headers(testToken, testChannelId, "exists"),
+			wantCode: http.StatusNotFound,
+		},
+		"invalid path": {
+			target:   "/user%40example.com",
+			headers:  headers(testToken, testChannelId, "exists"),
+			wantCode: http.StatusNotFound,
+		},
+		"pubsub notification": {
+			target: testPath + "?token=" + testToken,
+			body:   pubsubBody,
+		},
+		"pubsub bad token": {
+			target:   testPath + "?token=other-token",
+			body:     pubsubBody,
+			wantCode: http.StatusForbidden,
+		},
+		"pubsub bad subscription": {
+			target:   testPath + "?token=" + testToken,
+			body:     `{"message": {"data": "e30="}, "subscription": "other"}`,
+			wantCode: http.StatusForbidden,
+		},
+		"pubsub invalid envelope": {
+			target:   testPath + "?token=" + testToken,
+			body:     "{",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := newTestAdapter(tokensDir)
+			method := tc.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			r := httptest.NewRequest(method, tc.target, strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			notification, err := a.ParseEvent(r)
+			if tc.wantCode != 0 {
+				if got := StatusCode(err); got != tc.wantCode {
+					t.Fatalf("ParseEvent() status code = %d (%v), want %d", got, err, tc.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEvent() = %v", err)
+			}
+			if notification.User != testEmail || notification.Resource != testResource {
+				t.Errorf("ParseEvent() user, resource = %q, %q, want %q, %q", notification.User, notification.Resource, testEmail, testResource)
+			}
+		})
+	}
+}
+
+// TestParseEventRenewedChannel checks that the adapter accepts the notifications of a renewed channel as soon as
+// the channels of the mounted secret are updated, and rejects the ones of the replaced channel.
+func TestParseEventRenewedChannel(t *testing.T) {
+	tokensDir := newTokensDir(t)
+	defer os.RemoveAll(tokensDir)
+	a := newTestAdapter(tokensDir)
+
+	const renewedChannelId = "channel-2"
+	channels := fmt.Sprintf(`{%q: %q}`, gsuite.CursorKey(testEmail, testResource), renewedChannelId)
+	if err := ioutil.WriteFile(filepath.Join(tokensDir, channelsFile), []byte(channels), 0600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	for channelId, wantCode := range map[string]int{renewedChannelId: 0, testChannelId: http.StatusForbidden} {
+		r := httptest.NewRequest(http.MethodPost, testPath, nil)
+		r.Header.Set("X-"+headerChannelToken, testToken)
+		r.Header.Set("X-"+headerChannelId, channelId)
+		r.Header.Set("X-"+headerResourceState, "exists")
+		_, err := a.ParseEvent(r)
+		if wantCode == 0 && err != nil {
+			t.Errorf("ParseEvent() of channel %q = %v, want nil", channelId, err)
+		} else if wantCode != 0 && StatusCode(err) != wantCode {
+			t.Errorf("ParseEvent() of channel %q = %v, want status code %d", channelId, err, wantCode)
+		}
+	}
+}
+
+func TestNewCursors(t *testing.T) {
+	credentials, err := ioutil.TempFile("", "credentials")
+	if err != nil {
+		t.Fatalf("TempFile() = %v", err)
+	}
+	defer os.Remove(credentials.Name())
+	credentials.Close()
+
+	watches := []gsuite.Watch{{EmailAddress: testEmail, Resource: testResource}}
+	opts := Options{
+		Sink:            "http://sink.example.com",
+		CloudEvents:     sourcesv1alpha1.CloudEventsSpec{SpecVersion: cloudEventsVersionV1},
+		CredentialsFile: credentials.Name(),
+		Watches:         watches,
+	}
+
+	opts.Cursors = memoryCursors{gsuite.CursorKey(testEmail, testResource): "saved"}
+	a, err := New(fakeKind{}, opts)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if got := a.watches[watchKey{email: testEmail, resource: testResource}].cursor; got != "saved" {
+		t.Errorf("cursor = %q, want the saved one", got)
+	}
+
+	// The controller adds the cursors of the resources before it updates the watches of the adapter.
+	opts.Cursors = memoryCursors{gsuite.CursorKey(testEmail, "other"): "saved"}
+	if _, err := New(fakeKind{}, opts); err == nil {
+		t.Errorf("New() = nil, want an error for the resource without a cursor")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// CursorStore loads and saves the cursors of the watched resources, by gsuite.CursorKey, so that the
+// receive adapter does not read the same changes again when it restarts.
+type CursorStore interface {
+	// Load returns the saved cursors.
+	Load() (map[string]string, error)
+	// Save saves the cursor of a resource.
+	Save(key, cursor string) error
+}
+
+// configMapCursors keeps the cursors in the cursors ConfigMap of the source, which the controller creates.
+type configMapCursors struct {
+	configMaps corev1client.ConfigMapInterface
+	name       string
+}
+
+// NewConfigMapCursorStore returns a store of the cursors in the given ConfigMap, accessed with the in-cluster
+// config of the receive adapter.
+func NewConfigMapCursorStore(namespace, name string) (CursorStore, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &configMapCursors{configMaps: client.ConfigMaps(namespace), name: name}, nil
+}
+
+func (c *configMapCursors) Load() (map[string]string, error) {
+	cm, err := c.configMaps.Get(c.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return cm.Data, nil
+}
+
+func (c *configMapCursors) Save(key, cursor string) error {
+	// The controller adds and removes keys concurrently, so we only patch the one of the resource.
+	patch, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{key: cursor},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.configMaps.Patch(c.name, types.StrategicMergePatchType, patch)
+	return err
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"sync"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+)
+
+// defaultDedupWindowSize is the number of sent events the adapter remembers by default.
+const defaultDedupWindowSize = 10000
+
+// dedupWindow remembers the keys of the last sent events, so that the events of the notifications
+// Google redelivers are not sent twice. It is bounded, and forgets the oldest keys first.
+type dedupWindow struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+	// ring holds the keys in the order they were added, next being the position of the oldest one.
+	ring []string
+	next int
+}
+
+// dedupKey returns the key of the event in the window. The IDs of the events are unique per source.
+func dedupKey(event cloudevents.Event) string {
+	return event.Source() + " " + event.ID()
+}
+
+func newDedupWindow(size int) *dedupWindow {
+	return &dedupWindow{
+		keys: make(map[string]struct{}, size),
+		ring: make([]string, size),
+	}
+}
+
+// Seen returns true if the key is in the window.
+func (w *dedupWindow) Seen(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.keys[key]
+	return ok
+}
+
+// Add adds the key to the window, forgetting the oldest one if the window is full.
+func (w *dedupWindow) Add(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.keys[key]; ok {
+		return
+	}
+	if oldest := w.ring[w.next]; oldest != "" {
+		delete(w.keys, oldest)
+	}
+	w.ring[w.next] = key
+	w.keys[key] = struct{}{}
+	w.next = (w.next + 1) % len(w.ring)
+}
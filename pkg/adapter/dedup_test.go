@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+)
+
+func TestDedupWindowEviction(t *testing.T) {
+	w := newDedupWindow(2)
+	w.Add("a")
+	w.Add("b")
+	if !w.Seen("a") || !w.Seen("b") {
+		t.Fatalf("Seen() = false, want true for the keys within the window")
+	}
+
+	// Adding a key again does not take another slot.
+	w.Add("a")
+	if !w.Seen("b") {
+		t.Errorf("Seen(%q) = false after adding a seen key, want true", "b")
+	}
+
+	// The window is full, so the oldest key is forgotten.
+	w.Add("c")
+	if w.Seen("a") {
+		t.Errorf("Seen(%q) = true, want the oldest key evicted", "a")
+	}
+	if !w.Seen("b") || !w.Seen("c") {
+		t.Errorf("Seen() = false, want true for the last keys added")
+	}
+
+	w.Add("d")
+	w.Add("e")
+	for _, key := range []string{"a", "b", "c"} {
+		if w.Seen(key) {
+			t.Errorf("Seen(%q) = true after wrapping around, want false", key)
+		}
+	}
+	if !w.Seen("d") || !w.Seen("e") {
+		t.Errorf("Seen() = false after wrapping around, want true for the last keys added")
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	notification := &gsuite.Notification{}
+	event := gsuite.NewEvent("1", "type", "https://example.com/a", "", "", nil, notification)
+	sameSource := gsuite.NewEvent("1", "other-type", "https://example.com/a", "", "", nil, notification)
+	otherSource := gsuite.NewEvent("1", "type", "https://example.com/b", "", "", nil, notification)
+
+	w := newDedupWindow(defaultDedupWindowSize)
+	w.Add(dedupKey(event))
+	if !w.Seen(dedupKey(sameSource)) {
+		t.Errorf("Seen() = false for an event with the same source and ID, want true")
+	}
+	if w.Seen(dedupKey(otherSource)) {
+		t.Errorf("Seen() = true for an event with the same ID from another source, want false")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	"github.com/knative/pkg/logging"
+	"github.com/nachocano/gsuite-source/pkg/adapter/metrics"
+	"go.uber.org/zap"
+)
+
+// deliver sends the event to the sink, retrying the failed attempts with exponential backoff.
+// If they all fail, the event is sent to the dead letter sink, if any, with the same retries.
+func (a *Adapter) deliver(ctx context.Context, event cloudevents.Event) error {
+	err := a.sendWithRetries(ctx, a.sender, metrics.SinkPrimary, event)
+	if err == nil || a.deadLetterSender == nil {
+		return err
+	}
+	logging.FromContext(ctx).Warnw("Failed to deliver the event to the sink, sending it to the dead letter sink",
+		zap.String(logKeyEventId, event.ID()), zap.Error(err))
+	if dlsErr := a.sendWithRetries(ctx, a.deadLetterSender, metrics.SinkDeadLetter, event); dlsErr != nil {
+		return fmt.Errorf("%v, and to the dead letter sink: %v", err, dlsErr)
+	}
+	return nil
+}
+
+// sendWithRetries sends the event, retrying up to the configured number of times. The delay before
+// the first retry is the backoff delay, and it is doubled on every following retry. The attempts are
+// recorded in the metrics of the given sink, i.e., the sink or the dead letter sink.
+func (a *Adapter) sendWithRetries(ctx context.Context, s sender, sink string, event cloudevents.Event) error {
+	delay := a.backoffDelay
+	for retry := 0; ; retry++ {
+		start := time.Now()
+		err := s.Send(ctx, event)
+		metrics.SinkLatency.WithLabelValues(a.kind.Name(), sink).Observe(time.Since(start).Seconds())
+		if err == nil {
+			metrics.EventsSent.WithLabelValues(a.kind.Name(), sink).Inc()
+			return nil
+		}
+		metrics.EventSendFailures.WithLabelValues(a.kind.Name(), sink).Inc()
+		if retry >= a.retries {
+			return err
+		}
+		logging.FromContext(ctx).Warnw("Failed to send the event, retrying",
+			zap.String(logKeyEventId, event.ID()), zap.Duration("delay", delay), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
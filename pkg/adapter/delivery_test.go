@@ -0,0 +1,312 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+	"go.uber.org/zap"
+)
+
+const testBackoffDelay = 20 * time.Millisecond
+
+// fakeKind is a kind that only has a name, and whose clients are fakeClients.
+type fakeKind struct {
+	gsuite.Kind
+}
+
+func (fakeKind) Name() string {
+	return "fake"
+}
+
+func (fakeKind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	return &fakeClient{}, nil
+}
+
+// memoryCursors is a CursorStore in memory.
+type memoryCursors map[string]string
+
+func (c memoryCursors) Load() (map[string]string, error) {
+	return c, nil
+}
+
+func (c memoryCursors) Save(key, cursor string) error {
+	c[key] = cursor
+	return nil
+}
+
+// fakeClient returns the same events on every notification, along with the next cursor.
+type fakeClient struct {
+	gsuite.Client
+	events []cloudevents.Event
+}
+
+func (c *fakeClient) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	return c.events, cursor + "+", nil
+}
+
+// newDeliveryAdapter returns an adapter that sends CloudEvents 1.0 to the sink, and to the dead letter sink, if any.
+func newDeliveryAdapter(t *testing.T, sink, deadLetterSink string, retries int) *Adapter {
+	t.Helper()
+	ce := sourcesv1alpha1.CloudEventsSpec{SpecVersion: cloudEventsVersionV1, Mode: sourcesv1alpha1.CloudEventsModeBinary}
+	a := &Adapter{
+		kind:         fakeKind{},
+		logger:       zap.NewNop().Sugar(),
+		retries:      retries,
+		backoffDelay: testBackoffDelay,
+		dedup:        newDedupWindow(defaultDedupWindowSize),
+		cursors:      memoryCursors{},
+	}
+	var err error
+	if a.sender, err = newSender(sink, ce, defaultSendTimeout); err != nil {
+		t.Fatalf("newSender() = %v", err)
+	}
+	if deadLetterSink != "" {
+		if a.deadLetterSender, err = newSender(deadLetterSink, ce, defaultSendTimeout); err != nil {
+			t.Fatalf("newSender() = %v", err)
+		}
+	}
+	return a
+}
+
+func newTestEvent(id string) cloudevents.Event {
+	return gsuite.NewEvent(id, "type", "https://example.com", "", "", map[string]string{"id": id}, &gsuite.Notification{})
+}
+
+func TestDeliverRetries(t *testing.T) {
+	sink := gstesting.NewSink()
+	defer sink.Close()
+	sink.Script(http.StatusInternalServerError, http.StatusServiceUnavailable)
+
+	a := newDeliveryAdapter(t, sink.URL(), "", 3)
+	if err := a.deliver(context.Background(), newTestEvent("1")); err != nil {
+		t.Fatalf("deliver() = %v", err)
+	}
+
+	requests := sink.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("sink got %d requests, want 3", len(requests))
+	}
+	for _, r := range requests {
+		if id := r.Header.Get("ce-id"); id != "1" {
+			t.Errorf("ce-id = %q, want %q", id, "1")
+		}
+	}
+	// The delay before the first retry is the backoff delay, and it is doubled on the next one.
+	if d := requests[1].Time.Sub(requests[0].Time); d < testBackoffDelay {
+		t.Errorf("first retry after %s, want at least %s", d, testBackoffDelay)
+	}
+	if d := requests[2].Time.Sub(requests[1].Time); d < 2*testBackoffDelay {
+		t.Errorf("second retry after %s, want at least %s", d, 2*testBackoffDelay)
+	}
+}
+
+func TestDeliverDeadLetterSink(t *testing.T) {
+	sink := gstesting.NewSink()
+	defer sink.Close()
+	deadLetterSink := gstesting.NewSink()
+	defer deadLetterSink.Close()
+
+	const retries = 2
+	for i := 0; i <= retries; i++ {
+		sink.Script(http.StatusInternalServerError)
+	}
+
+	a := newDeliveryAdapter(t, sink.URL(), deadLetterSink.URL(), retries)
+	if err := a.deliver(context.Background(), newTestEvent("1")); err != nil {
+		t.Fatalf("deliver() = %v", err)
+	}
+	if got := len(sink.Requests()); got != retries+1 {
+		t.Errorf("sink got %d requests, want %d", got, retries+1)
+	}
+	requests := deadLetterSink.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("dead letter sink got %d requests, want 1", len(requests))
+	}
+	if id := requests[0].Header.Get("ce-id"); id != "1" {
+		t.Errorf("dead letter ce-id = %q, want %q", id, "1")
+	}
+}
+
+func TestDeliverFailure(t *testing.T) {
+	sink := gstesting.NewSink()
+	defer sink.Close()
+	deadLetterSink := gstesting.NewSink()
+	defer deadLetterSink.Close()
+	sink.Script(http.StatusInternalServerError, http.StatusInternalServerError)
+	deadLetterSink.Script(http.StatusInternalServerError, http.StatusInternalServerError)
+
+	a := newDeliveryAdapter(t, sink.URL(), deadLetterSink.URL(), 1)
+	err := a.deliver(context.Background(), newTestEvent("1"))
+	if err == nil || !strings.Contains(err.Error(), "dead letter sink") {
+		t.Errorf("deliver() = %v, want the errors of the sink and the dead letter sink", err)
+	}
+	if got := len(deadLetterSink.Requests()); got != 2 {
+		t.Errorf("dead letter sink got %d requests, want 2", got)
+	}
+}
+
+func TestServeHTTPDelivery(t *testing.T) {
+	tokensDir := newTokensDir(t)
+	defer os.RemoveAll(tokensDir)
+
+	testCases := map[string]struct {
+		sinkCodes  []int
+		wantCode   int
+		wantCursor string
+		wantSaved  string
+	}{
+		"delivered": {
+			wantCode:   http.StatusOK,
+			wantCursor: "cursor+",
+			wantSaved:  "cursor+",
+		},
+		"delivery failure": {
+			sinkCodes: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantCode:  http.StatusServiceUnavailable,
+			// The cursor does not advance, nor is it saved, so that the events are read again on the redelivery.
+			wantCursor: "cursor",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sink := gstesting.NewSink()
+			defer sink.Close()
+			sink.Script(tc.sinkCodes...)
+
+			a := newDeliveryAdapter(t, sink.URL(), "", 1)
+			a.tokensDir = tokensDir
+			w := &watch{
+				gsClient:  &fakeClient{events: []cloudevents.Event{newTestEvent("1")}},
+				cursorKey: gsuite.CursorKey(testEmail, testResource),
+				cursor:    "cursor",
+			}
+			a.watches = map[watchKey]*watch{{email: testEmail, resource: testResource}: w}
+
+			r := httptest.NewRequest(http.MethodPost, testPath, nil)
+			r.Header.Set("X-"+headerChannelToken, testToken)
+			r.Header.Set("X-"+headerChannelId, testChannelId)
+			r.Header.Set("X-"+headerResourceState, "exists")
+			rec := httptest.NewRecorder()
+			a.ServeHTTP(rec, r)
+
+			if rec.Code != tc.wantCode {
+				t.Errorf("status code = %d, want %d", rec.Code, tc.wantCode)
+			}
+			if w.cursor != tc.wantCursor {
+				t.Errorf("cursor = %q, want %q", w.cursor, tc.wantCursor)
+			}
+			if saved := a.cursors.(memoryCursors)[w.cursorKey]; saved != tc.wantSaved {
+				t.Errorf("saved cursor = %q, want %q", saved, tc.wantSaved)
+			}
+		})
+	}
+}
+
+// newHangingSink returns a sink that never responds, until the returned func is called.
+func newHangingSink() (*httptest.Server, func()) {
+	release := make(chan struct{})
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	return sink, func() {
+		close(release)
+		sink.Close()
+	}
+}
+
+func TestDeliverSinkTimeout(t *testing.T) {
+	sink, closeSink := newHangingSink()
+	defer closeSink()
+
+	const timeout = 50 * time.Millisecond
+	a := newDeliveryAdapter(t, sink.URL, "", 1)
+	var err error
+	ce := sourcesv1alpha1.CloudEventsSpec{SpecVersion: cloudEventsVersionV1, Mode: sourcesv1alpha1.CloudEventsModeBinary}
+	if a.sender, err = newSender(sink.URL, ce, timeout); err != nil {
+		t.Fatalf("newSender() = %v", err)
+	}
+
+	start := time.Now()
+	if err := a.deliver(context.Background(), newTestEvent("1")); err == nil {
+		t.Fatalf("deliver() = nil, want a timeout")
+	}
+	// Both attempts time out, with the backoff delay in between.
+	if d := time.Since(start); d > 2*timeout+testBackoffDelay+time.Second {
+		t.Errorf("deliver() returned after %s, want the attempts to time out after %s", d, timeout)
+	}
+}
+
+func TestServeHTTPCanceled(t *testing.T) {
+	tokensDir := newTokensDir(t)
+	defer os.RemoveAll(tokensDir)
+	sink, closeSink := newHangingSink()
+	defer closeSink()
+
+	a := newDeliveryAdapter(t, sink.URL, "", 0)
+	a.tokensDir = tokensDir
+	w := &watch{
+		gsClient:  &fakeClient{events: []cloudevents.Event{newTestEvent("1")}},
+		cursorKey: gsuite.CursorKey(testEmail, testResource),
+		cursor:    "cursor",
+	}
+	a.watches = map[watchKey]*watch{{email: testEmail, resource: testResource}: w}
+
+	serve := func() int {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		r := httptest.NewRequest(http.MethodPost, testPath, nil).WithContext(ctx)
+		r.Header.Set("X-"+headerChannelToken, testToken)
+		r.Header.Set("X-"+headerChannelId, testChannelId)
+		r.Header.Set("X-"+headerResourceState, "exists")
+		rec := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			a.ServeHTTP(rec, r)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("ServeHTTP() still delivering after the request was canceled")
+		}
+		return rec.Code
+	}
+
+	// The second notification is not blocked by the first one, which released the cursor when canceled.
+	for i := 0; i < 2; i++ {
+		if code := serve(); code != http.StatusServiceUnavailable {
+			t.Errorf("status code = %d, want %d", code, http.StatusServiceUnavailable)
+		}
+	}
+	if w.cursor != "cursor" {
+		t.Errorf("cursor = %q, want it not to advance", w.cursor)
+	}
+}
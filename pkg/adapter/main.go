@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knative/pkg/logging"
+	"github.com/nachocano/gsuite-source/pkg/adapter/metrics"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"go.uber.org/zap"
+)
+
+const (
+	// Environment variable containing the HTTP port
+	envPort = "PORT"
+	// Environment variable containing the port the metrics are exposed on
+	envMetricsPort = "METRICS_PORT"
+	// defaultMetricsPort is the metrics port, if none is given. The queue-proxy of the
+	// Knative revisions already uses 9090.
+	defaultMetricsPort = "9095"
+	// Environment variable containing the sink
+	envSink = "SINK"
+	// Environment variable containing the JSON array with the users and resources to watch
+	envWatches = "WATCHES"
+	// Environment variable containing the name of the ConfigMap with the cursors of the watched resources
+	envCursorsConfigMap = "CURSORS_CONFIGMAP"
+	// Environment variable containing the path to the service account credentials
+	envCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+	// Environment variable containing the directory with the channel tokens
+	envChannelTokensDir = "CHANNEL_TOKENS_DIR"
+	// Environment variable containing how often all the resources are polled, only set in poll mode
+	envPollInterval = "POLL_INTERVAL"
+	// Environment variable containing the CloudEvents spec version
+	envCloudEventsSpecVersion = "CE_SPEC_VERSION"
+	// Environment variable containing the CloudEvents HTTP content mode, i.e., binary or structured
+	envCloudEventsMode = "CE_MODE"
+	// Environment variable containing the dead letter sink, if any
+	envDeadLetterSink = "DEAD_LETTER_SINK"
+	// Environment variable containing the number of times a failed delivery is retried
+	envRetries = "RETRIES"
+	// Environment variable containing the delay before the first retry of a failed delivery
+	envBackoffDelay = "BACKOFF_DELAY"
+	// Environment variables containing the name and namespace of the source
+	envName      = "NAME"
+	envNamespace = "NAMESPACE"
+	// Environment variable containing the JSON zap logger config, as in the zap-logger-config key of the config-logging ConfigMap
+	envLoggingConfig = "LOGGING_CONFIG"
+	// Environment variable containing the log level, as in the loglevel.adapter key of the config-logging ConfigMap
+	envLoggingLevel = "LOGGING_LEVEL"
+)
+
+// Main runs the receive adapter of the given kind, configured from the environment.
+func Main(kind gsuite.Kind) {
+	name := strings.Title(kind.Name())
+	// The levels are read from the config-logging ConfigMap of the controller, which passes them in the environment.
+	logger, _ := logging.NewLogger(os.Getenv(envLoggingConfig), os.Getenv(envLoggingLevel))
+	logger = logger.Named(kind.Name()).With(
+		zap.String(logKeySource, os.Getenv(envName)),
+		zap.String(logKeyNamespace, os.Getenv(envNamespace)))
+	defer logger.Sync()
+	logger.Infof("Starting %s Adapter...", name)
+
+	sink := os.Getenv(envSink)
+	if sink == "" {
+		logger.Fatal("No sink given")
+	}
+	logger.Infof("Sink %s", sink)
+
+	credentials := os.Getenv(envCredentials)
+	if credentials == "" {
+		logger.Fatal("No credentials given")
+	}
+
+	tokensDir := os.Getenv(envChannelTokensDir)
+	if tokensDir == "" {
+		logger.Fatal("No channel tokens directory given")
+	}
+
+	var watches []gsuite.Watch
+	if err := json.Unmarshal([]byte(os.Getenv(envWatches)), &watches); err != nil {
+		logger.Fatalf("Invalid watches given: %v", err)
+	}
+	if len(watches) == 0 {
+		logger.Fatal("No watches given")
+	}
+
+	cursorsConfigMap := os.Getenv(envCursorsConfigMap)
+	if cursorsConfigMap == "" {
+		logger.Fatal("No cursors ConfigMap given")
+	}
+	cursors, err := NewConfigMapCursorStore(os.Getenv(envNamespace), cursorsConfigMap)
+	if err != nil {
+		logger.Fatalw("Failed to access the cursors", zap.Error(err))
+	}
+
+	var pollInterval time.Duration
+	if v := os.Getenv(envPollInterval); v != "" {
+		var err error
+		if pollInterval, err = time.ParseDuration(v); err != nil || pollInterval <= 0 {
+			logger.Fatalf("Invalid poll interval given: %q", v)
+		}
+		logger.Infof("Poll interval %s", pollInterval)
+	}
+
+	deadLetterSink := os.Getenv(envDeadLetterSink)
+	if deadLetterSink != "" {
+		logger.Infof("Dead Letter Sink %s", deadLetterSink)
+	}
+
+	retries := sourcesv1alpha1.DefaultDeliveryRetries
+	if v := os.Getenv(envRetries); v != "" {
+		var err error
+		if retries, err = strconv.Atoi(v); err != nil || retries < 0 {
+			logger.Fatalf("Invalid retries given: %q", v)
+		}
+	}
+	backoffDelay := sourcesv1alpha1.DefaultDeliveryBackoffDelay
+	if v := os.Getenv(envBackoffDelay); v != "" {
+		var err error
+		if backoffDelay, err = time.ParseDuration(v); err != nil || backoffDelay <= 0 {
+			logger.Fatalf("Invalid backoff delay given: %q", v)
+		}
+	}
+	logger.Infof("Retries %d Backoff Delay %s", retries, backoffDelay)
+
+	// The spec of the sources has the defaults of the unset fields.
+	ce := (&sourcesv1alpha1.GSuiteSourceSpec{
+		CloudEvents: &sourcesv1alpha1.CloudEventsSpec{
+			SpecVersion: os.Getenv(envCloudEventsSpecVersion),
+			Mode:        sourcesv1alpha1.CloudEventsMode(os.Getenv(envCloudEventsMode)),
+		},
+	}).GetCloudEvents()
+	logger.Infof("CloudEvents %s %s", ce.SpecVersion, ce.Mode)
+
+	port := os.Getenv(envPort)
+	if port == "" {
+		port = "8080"
+	}
+	logger.Infof("Port %s", port)
+
+	ra, err := New(kind, Options{
+		Sink:            sink,
+		DeadLetterSink:  deadLetterSink,
+		Retries:         retries,
+		BackoffDelay:    backoffDelay,
+		CloudEvents:     ce,
+		CredentialsFile: credentials,
+		TokensDir:       tokensDir,
+		PollInterval:    pollInterval,
+		Watches:         watches,
+		Cursors:         cursors,
+		Logger:          logger,
+	})
+	if err != nil {
+		logger.Fatalw(fmt.Sprintf("Failed to create %s Adapter", name), zap.Error(err))
+	}
+
+	metricsPort := os.Getenv(envMetricsPort)
+	if metricsPort == "" {
+		metricsPort = defaultMetricsPort
+	}
+	logger.Infof("Metrics Port %s", metricsPort)
+	go func() {
+		if err := metrics.ServeAdapter(fmt.Sprintf(":%s", metricsPort)); err != nil {
+			logger.Errorw("Failed to serve the metrics", zap.Error(err))
+		}
+	}()
+
+	go ra.Poll(context.Background())
+
+	http.Handle("/", ra)
+
+	addr := fmt.Sprintf(":%s", port)
+	if err := http.ListenAndServe(addr, nil); err != nil {
+		logger.Fatalw(fmt.Sprintf("Failed to start %s Adapter", name), zap.Error(err))
+	}
+
+	logger.Infof("Started %s Adapter", name)
+}
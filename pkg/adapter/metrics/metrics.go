@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the receive adapters.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "gsuite"
+
+	// LabelKind is the label with the name of the G Suite product, e.g., "drive".
+	LabelKind = "kind"
+	// LabelResourceState is the label with the resource state of a notification, e.g., "exists".
+	LabelResourceState = "resource_state"
+	// LabelSink is the label with the sink an event is sent to, i.e., SinkPrimary or SinkDeadLetter.
+	LabelSink = "sink"
+
+	SinkPrimary    = "sink"
+	SinkDeadLetter = "dead_letter_sink"
+)
+
+var (
+	// NotificationsReceived counts the push notifications received, by resource state.
+	NotificationsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "adapter",
+		Name:      "notifications_received_total",
+		Help:      "Number of push notifications received, by resource state.",
+	}, []string{LabelKind, LabelResourceState})
+
+	// SyncHandshakes counts the sync notifications sent when the channels are created.
+	SyncHandshakes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "adapter",
+		Name:      "sync_handshakes_total",
+		Help:      "Number of sync notifications received from newly created channels.",
+	}, []string{LabelKind})
+
+	// EventsSent counts the CloudEvents successfully sent, to the sink or to the dead letter sink.
+	EventsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "adapter",
+		Name:      "events_sent_total",
+		Help:      "Number of CloudEvents successfully sent.",
+	}, []string{LabelKind, LabelSink})
+
+	// EventSendFailures counts the failed attempts to send a CloudEvent, including the ones retried.
+	EventSendFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "adapter",
+		Name:      "event_send_failures_total",
+		Help:      "Number of failed attempts to send a CloudEvent.",
+	}, []string{LabelKind, LabelSink})
+
+	// SinkLatency is the duration of the attempts to send a CloudEvent.
+	SinkLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "adapter",
+		Name:      "sink_latency_seconds",
+		Help:      "Duration of the attempts to send a CloudEvent, in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{LabelKind, LabelSink})
+)
+
+// adapterRegistry holds the metrics of the receive adapters, along with the ones of the Go runtime and the process.
+var adapterRegistry = prometheus.NewRegistry()
+
+func init() {
+	adapterRegistry.MustRegister(
+		NotificationsReceived,
+		SyncHandshakes,
+		EventsSent,
+		EventSendFailures,
+		SinkLatency,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+}
+
+// ServeAdapter exposes the metrics of the receive adapter on the /metrics path of the given address.
+func ServeAdapter(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(adapterRegistry, promhttp.HandlerOpts{}))
+	return http.ListenAndServe(addr, mux)
+}
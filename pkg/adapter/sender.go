@@ -0,0 +1,210 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	"github.com/cloudevents/sdk-go/pkg/cloudevents/client"
+	cehttp "github.com/cloudevents/sdk-go/pkg/cloudevents/transport/http"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+)
+
+const (
+	// cloudEventsVersionV1 is the version 1.0 of the CloudEvents spec, not supported by the CloudEvents SDK yet.
+	cloudEventsVersionV1 = "1.0"
+
+	// contentTypeStructured is the content type of the CloudEvents 1.0 in structured mode.
+	contentTypeStructured = "application/cloudevents+json; charset=utf-8"
+	// headerPrefix is the prefix of the HTTP headers of the CloudEvents 1.0 attributes in binary mode.
+	headerPrefix = "ce-"
+
+	// defaultSendTimeout is how long a delivery attempt waits for the sink to respond by default.
+	defaultSendTimeout = 30 * time.Second
+)
+
+// sender sends CloudEvents to the sink.
+type sender interface {
+	Send(ctx context.Context, event cloudevents.Event) error
+}
+
+// newSender returns a sender of CloudEvents of the given spec version and HTTP content mode, whose requests
+// time out after the given timeout. The versions 0.2 and 0.3 are sent with the CloudEvents SDK, and the
+// version 1.0 is encoded by the adapter.
+func newSender(sink string, ce sourcesv1alpha1.CloudEventsSpec, timeout time.Duration) (sender, error) {
+	structured := ce.Mode == sourcesv1alpha1.CloudEventsModeStructured
+	switch ce.SpecVersion {
+	case cloudevents.CloudEventsVersionV02, cloudevents.CloudEventsVersionV03:
+		encoding := cehttp.WithBinaryEncoding()
+		if structured {
+			encoding = cehttp.WithStructuredEncoding()
+		}
+		t, err := cehttp.New(cehttp.WithTarget(sink), encoding)
+		if err != nil {
+			return nil, err
+		}
+		t.Client = &http.Client{Timeout: timeout}
+		// The G Suite clients set the IDs and times of the events.
+		c, err := client.New(t)
+		if err != nil {
+			return nil, err
+		}
+		return &sdkSender{client: c, specVersion: ce.SpecVersion}, nil
+	case cloudEventsVersionV1:
+		return &v1Sender{client: &http.Client{Timeout: timeout}, sink: sink, structured: structured}, nil
+	}
+	return nil, fmt.Errorf("unsupported CloudEvents spec version %q", ce.SpecVersion)
+}
+
+// sdkSender sends CloudEvents with the CloudEvents SDK, converting them to its spec version.
+type sdkSender struct {
+	client      client.Client
+	specVersion string
+}
+
+func (s *sdkSender) Send(ctx context.Context, event cloudevents.Event) error {
+	switch s.specVersion {
+	case cloudevents.CloudEventsVersionV02:
+		event.Context = event.Context.AsV02()
+	case cloudevents.CloudEventsVersionV03:
+		event.Context = event.Context.AsV03()
+	}
+	_, err := s.client.Send(ctx, event)
+	return err
+}
+
+// v1Sender sends CloudEvents 1.0 over HTTP, in binary or structured mode.
+type v1Sender struct {
+	client     *http.Client
+	sink       string
+	structured bool
+}
+
+func (s *v1Sender) Send(ctx context.Context, event cloudevents.Event) error {
+	req, err := s.newRequest(event)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("sink responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// newRequest encodes the event as a CloudEvents 1.0 HTTP request to the sink.
+func (s *v1Sender) newRequest(event cloudevents.Event) (*http.Request, error) {
+	ec := event.Context.AsV03()
+	attributes := map[string]string{
+		"specversion": cloudEventsVersionV1,
+		"id":          ec.ID,
+		"source":      ec.Source.String(),
+		"type":        ec.Type,
+	}
+	if ec.Subject != nil {
+		attributes["subject"] = *ec.Subject
+	}
+	if ec.Time != nil {
+		attributes["time"] = ec.Time.Time.UTC().Format(time.RFC3339Nano)
+	}
+	if ec.DataContentType != nil {
+		attributes["datacontenttype"] = *ec.DataContentType
+	}
+	for name, value := range ec.Extensions {
+		attributes[extensionName(name)] = fmt.Sprint(value)
+	}
+
+	var data []byte
+	if event.Data != nil {
+		var err error
+		if data, err = eventData(event); err != nil {
+			return nil, err
+		}
+	}
+
+	var body []byte
+	header := make(http.Header)
+	if s.structured {
+		structured := make(map[string]interface{}, len(attributes)+1)
+		for name, value := range attributes {
+			structured[name] = value
+		}
+		if data != nil {
+			// All the G Suite events carry JSON data.
+			structured["data"] = json.RawMessage(data)
+		}
+		var err error
+		if body, err = json.Marshal(structured); err != nil {
+			return nil, err
+		}
+		header.Set("Content-Type", contentTypeStructured)
+	} else {
+		for name, value := range attributes {
+			if name == "datacontenttype" {
+				header.Set("Content-Type", value)
+				continue
+			}
+			header.Set(headerPrefix+name, value)
+		}
+		body = data
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.sink, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	return req, nil
+}
+
+// eventData returns the JSON encoded data of the event.
+func eventData(event cloudevents.Event) ([]byte, error) {
+	if b, ok := event.Data.([]byte); ok {
+		return b, nil
+	}
+	return json.Marshal(event.Data)
+}
+
+// extensionName returns a valid CloudEvents 1.0 attribute name for the extension, i.e., lowercase
+// letters and digits only. For example, Goog-Resource-ID becomes googresourceid.
+func extensionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+)
+
+// SetDefaults implements apis.Defaultable.
+func (s *CalendarSource) SetDefaults(ctx context.Context) {
+	s.Spec.SetDefaults(ctx)
+}
+
+// SetDefaults sets the defaults of the CalendarSource spec.
+// If no calendars are set, the primary calendar of every user is watched.
+func (s *CalendarSourceSpec) SetDefaults(ctx context.Context) {
+	s.GSuiteSourceSpec.SetDefaults(ctx)
+	s.GSuiteUsersSpec.SetDefaults(ctx, s.EmailAddress)
+	if !s.AllCalendars && len(s.CalendarIds) == 0 {
+		s.CalendarIds = []string{"primary"}
+	}
+}
@@ -19,120 +19,58 @@ package v1alpha1
 import (
 	"github.com/knative/pkg/apis/duck"
 	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
-var _ runtime.Object = (*CalendarSource)(nil)
+var _ GSuiteMultiUserSource = (*CalendarSource)(nil)
 
 var _ = duck.VerifyType(&CalendarSource{}, &duckv1alpha1.Conditions{})
 
 type CalendarSourceSpec struct {
-	// TODO be able to set many, so that we create a single Service listening to events from many calendars.
-	EmailAddress   string                   `json:"emailAddress"`
-	GcpCredsSecret corev1.SecretKeySelector `json:"gcpCredsSecret"`
-	Sink           *corev1.ObjectReference  `json:"sink"`
+	GSuiteSourceSpec `json:",inline"`
+	GSuiteUsersSpec  `json:",inline"`
+
+	// CalendarIds are the IDs of the calendars to watch of every user, e.g., shared, room or secondary calendars.
+	// If not set, the primary calendar of every user is watched.
+	CalendarIds []string `json:"calendarIds,omitempty"`
+	// AllCalendars watches all the calendars in the calendar list of every user, instead of CalendarIds.
+	AllCalendars bool `json:"allCalendars,omitempty"`
 }
 
 const (
 	CalendarSourceEventType = "org.nachocano.source.gsuite.calendar"
-	CalendarSourceToken     = CalendarSourceEventType
-)
 
-const (
-	CalendarSourceConditionReady                                      = duckv1alpha1.ConditionReady
-	CalendarSourceConditionSecretsProvided duckv1alpha1.ConditionType = "SecretsProvided"
-	CalendarSourceConditionSinkProvided    duckv1alpha1.ConditionType = "SinkProvided"
-	CalendarSourceConditionServiceProvided duckv1alpha1.ConditionType = "ServiceProvided"
-	CalendarSourceConditionWebHookProvided duckv1alpha1.ConditionType = "WebHookProvided"
+	// Types of the CloudEvents sent for each calendar event that changed.
+	CalendarSourceCreatedEventType   = CalendarSourceEventType + ".created"
+	CalendarSourceUpdatedEventType   = CalendarSourceEventType + ".updated"
+	CalendarSourceCancelledEventType = CalendarSourceEventType + ".cancelled"
 )
 
-var calendarSourceCondSet = duckv1alpha1.NewLivingConditionSet(
-	CalendarSourceConditionSecretsProvided,
-	CalendarSourceConditionSinkProvided,
-	CalendarSourceConditionServiceProvided,
-	CalendarSourceConditionWebHookProvided,
+const (
+	CalendarSourceConditionReady           = GSuiteSourceConditionReady
+	CalendarSourceConditionSecretsProvided = GSuiteSourceConditionSecretsProvided
+	CalendarSourceConditionSinkProvided    = GSuiteSourceConditionSinkProvided
+	CalendarSourceConditionServiceProvided = GSuiteSourceConditionServiceProvided
+	CalendarSourceConditionWebHookProvided = GSuiteSourceConditionWebHookProvided
 )
 
 type CalendarSourceStatus struct {
-	duckv1alpha1.Status `json:",inline"`
-
-	WebhookId         string `json:"webhookId,omitempty"`
-	WebhookResourceId string `json:"webhookResourceId,omitempty"`
-
-	SinkURI string `json:"sinkUri,omitempty"`
-}
-
-// GetCondition returns the condition currently associated with the given type, or nil.
-func (s *CalendarSourceStatus) GetCondition(t duckv1alpha1.ConditionType) *duckv1alpha1.Condition {
-	return calendarSourceCondSet.Manage(s).GetCondition(t)
-}
-
-// IsReady returns true if the resource is ready overall.
-func (s *CalendarSourceStatus) IsReady() bool {
-	return calendarSourceCondSet.Manage(s).IsHappy()
-}
-
-// InitializeConditions sets relevant unset conditions to Unknown state.
-func (s *CalendarSourceStatus) InitializeConditions() {
-	calendarSourceCondSet.Manage(s).InitializeConditions()
-}
-
-// MarkService sets the condition that the source has a service configured.
-func (s *CalendarSourceStatus) MarkService() {
-	calendarSourceCondSet.Manage(s).MarkTrue(CalendarSourceConditionServiceProvided)
-}
-
-// MarkNoService sets the condition that the source does not have a valid service.
-func (s *CalendarSourceStatus) MarkNoService(reason, messageFormat string, messageA ...interface{}) {
-	calendarSourceCondSet.Manage(s).MarkFalse(CalendarSourceConditionServiceProvided, reason, messageFormat, messageA...)
-}
-
-// MarkWebHook sets the condition that the source has a webhook configured.
-func (s *CalendarSourceStatus) MarkWebHook(id, resourceId string) {
-	s.WebhookId = id
-	s.WebhookResourceId = resourceId
-	if len(id) > 0 && len(resourceId) > 0 {
-		calendarSourceCondSet.Manage(s).MarkTrue(CalendarSourceConditionWebHookProvided)
-	} else {
-		calendarSourceCondSet.Manage(s).MarkFalse(CalendarSourceConditionWebHookProvided,
-			"WebHookParamsEmpty", "WebHookParams empty.")
-	}
-
-}
-
-// MarkNoWebHook sets the condition that the source does not have a valid webhook.
-func (s *CalendarSourceStatus) MarkNoWebHook(reason, messageFormat string, messageA ...interface{}) {
-	s.WebhookId = ""
-	s.WebhookResourceId = ""
-	calendarSourceCondSet.Manage(s).MarkFalse(CalendarSourceConditionWebHookProvided, reason, messageFormat, messageA...)
-}
-
-// MarkSecrets sets the condition that the source has a valid secret.
-func (s *CalendarSourceStatus) MarkSecrets() {
-	calendarSourceCondSet.Manage(s).MarkTrue(CalendarSourceConditionSecretsProvided)
+	GSuiteSourceStatus `json:",inline"`
 }
 
-// MarkNoSecrets sets the condition that the source does not have a valid secret.
-func (s *CalendarSourceStatus) MarkNoSecrets(reason, messageFormat string, messageA ...interface{}) {
-	calendarSourceCondSet.Manage(s).MarkFalse(CalendarSourceConditionSecretsProvided, reason, messageFormat, messageA...)
+// GetGSuiteSpec implements GSuiteSource.
+func (s *CalendarSource) GetGSuiteSpec() *GSuiteSourceSpec {
+	return &s.Spec.GSuiteSourceSpec
 }
 
-// MarkSink sets the condition that the source has a sink configured.
-func (s *CalendarSourceStatus) MarkSink(uri string) {
-	s.SinkURI = uri
-	if len(uri) > 0 {
-		calendarSourceCondSet.Manage(s).MarkTrue(CalendarSourceConditionSinkProvided)
-	} else {
-		calendarSourceCondSet.Manage(s).MarkUnknown(CalendarSourceConditionSinkProvided,
-			"SinkEmpty", "Sink has resolved to empty.")
-	}
+// GetGSuiteStatus implements GSuiteSource.
+func (s *CalendarSource) GetGSuiteStatus() *GSuiteSourceStatus {
+	return &s.Status.GSuiteSourceStatus
 }
 
-// MarkNoSink sets the condition that the source does not have a sink configured.
-func (s *CalendarSourceStatus) MarkNoSink(reason, messageFormat string, messageA ...interface{}) {
-	calendarSourceCondSet.Manage(s).MarkFalse(CalendarSourceConditionSinkProvided, reason, messageFormat, messageA...)
+// GetGSuiteUsersSpec implements GSuiteMultiUserSource.
+func (s *CalendarSource) GetGSuiteUsersSpec() *GSuiteUsersSpec {
+	return &s.Spec.GSuiteUsersSpec
 }
 
 // +genclient
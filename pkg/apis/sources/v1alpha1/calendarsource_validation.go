@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/knative/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *CalendarSource) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.Spec.Validate(ctx).ViaField("spec")
+	if apis.IsInUpdate(ctx) {
+		original := apis.GetBaseline(ctx).(*CalendarSource)
+		errs = errs.Also(s.Spec.GSuiteSourceSpec.CheckImmutableFields(ctx, &original.Spec.GSuiteSourceSpec).ViaField("spec"))
+	}
+	return errs
+}
+
+// Validate validates the CalendarSource spec.
+func (s *CalendarSourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.GSuiteSourceSpec.Validate(ctx).Also(s.GSuiteUsersSpec.Validate(ctx, s.EmailAddress))
+	for i, id := range s.CalendarIds {
+		if id == "" {
+			errs = errs.Also(apis.ErrInvalidArrayValue(id, "calendarIds", i))
+		}
+	}
+	if s.AllCalendars && len(s.CalendarIds) > 0 {
+		errs = errs.Also(apis.ErrMultipleOneOf("calendarIds", "allCalendars"))
+	}
+	return errs
+}
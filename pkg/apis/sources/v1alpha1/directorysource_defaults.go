@@ -0,0 +1,34 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+)
+
+// SetDefaults implements apis.Defaultable.
+func (s *DirectorySource) SetDefaults(ctx context.Context) {
+	s.Spec.SetDefaults(ctx)
+}
+
+// SetDefaults sets the defaults of the DirectorySource spec. All the user events are watched by default.
+func (s *DirectorySourceSpec) SetDefaults(ctx context.Context) {
+	s.GSuiteSourceSpec.SetDefaults(ctx)
+	if len(s.Events) == 0 {
+		s.Events = append([]string(nil), DirectorySourceUserEvents...)
+	}
+}
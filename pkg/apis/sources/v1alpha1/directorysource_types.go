@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/knative/pkg/apis/duck"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ GSuiteSource = (*DirectorySource)(nil)
+
+var _ = duck.VerifyType(&DirectorySource{}, &duckv1alpha1.Conditions{})
+
+// DirectorySourceSpec watches the users and groups of the G Suite domain. Its EmailAddress is the
+// G Suite admin to impersonate, and must be set.
+type DirectorySourceSpec struct {
+	GSuiteSourceSpec `json:",inline"`
+
+	// Domain is the domain whose users are watched. If not set, the users of all the domains of the account are watched.
+	Domain string `json:"domain,omitempty"`
+	// Events are the user events to watch, i.e., add, delete, update, makeAdmin or undelete.
+	// It defaults to all of them.
+	Events []string `json:"events,omitempty"`
+	// Groups are the email addresses of the groups whose membership changes are watched.
+	Groups []string `json:"groups,omitempty"`
+}
+
+const (
+	DirectorySourceEventType = "org.nachocano.source.gsuite.directory"
+
+	// DirectorySourceUserEventType is the prefix of the types of the CloudEvents sent for each user event,
+	// i.e., org.nachocano.source.gsuite.directory.user.<event>.
+	DirectorySourceUserEventType = DirectorySourceEventType + ".user"
+
+	// Types of the CloudEvents sent for each group membership change.
+	DirectorySourceMemberAddedEventType   = DirectorySourceEventType + ".group.member.added"
+	DirectorySourceMemberRemovedEventType = DirectorySourceEventType + ".group.member.removed"
+)
+
+// DirectorySourceUserEvents are the user events that can be watched.
+var DirectorySourceUserEvents = []string{"add", "delete", "update", "makeAdmin", "undelete"}
+
+const (
+	DirectorySourceConditionReady           = GSuiteSourceConditionReady
+	DirectorySourceConditionSecretsProvided = GSuiteSourceConditionSecretsProvided
+	DirectorySourceConditionSinkProvided    = GSuiteSourceConditionSinkProvided
+	DirectorySourceConditionServiceProvided = GSuiteSourceConditionServiceProvided
+	DirectorySourceConditionWebHookProvided = GSuiteSourceConditionWebHookProvided
+)
+
+type DirectorySourceStatus struct {
+	GSuiteSourceStatus `json:",inline"`
+}
+
+// GetGSuiteSpec implements GSuiteSource.
+func (s *DirectorySource) GetGSuiteSpec() *GSuiteSourceSpec {
+	return &s.Spec.GSuiteSourceSpec
+}
+
+// GetGSuiteStatus implements GSuiteSource.
+func (s *DirectorySource) GetGSuiteStatus() *GSuiteSourceStatus {
+	return &s.Status.GSuiteSourceStatus
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DirectorySource is the Schema for the directorysources API.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:categories=all,knative,eventing,sources
+type DirectorySource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DirectorySourceSpec   `json:"spec,omitempty"`
+	Status DirectorySourceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DirectorySourceList contains a list of DirectorySource.
+type DirectorySourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DirectorySource `json:"items"`
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/knative/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *DirectorySource) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.Spec.Validate(ctx).ViaField("spec")
+	if apis.IsInUpdate(ctx) {
+		original := apis.GetBaseline(ctx).(*DirectorySource)
+		errs = errs.Also(s.Spec.GSuiteSourceSpec.CheckImmutableFields(ctx, &original.Spec.GSuiteSourceSpec).ViaField("spec"))
+	}
+	return errs
+}
+
+// Validate validates the DirectorySource spec. The users and groups can only be watched by a G Suite admin, and
+// the users cannot be polled, as they are carried by the push notifications.
+func (s *DirectorySourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.GSuiteSourceSpec.Validate(ctx).Also(s.GSuiteSourceSpec.validatePushMode())
+	if s.EmailAddress == "" {
+		errs = errs.Also(apis.ErrMissingField("emailAddress"))
+	}
+	for i, event := range s.Events {
+		valid := false
+		for _, e := range DirectorySourceUserEvents {
+			valid = valid || event == e
+		}
+		if !valid {
+			errs = errs.Also(apis.ErrInvalidArrayValue(event, "events", i))
+		}
+	}
+	for i, group := range s.Groups {
+		if !isEmailAddress(group) {
+			errs = errs.Also(apis.ErrInvalidArrayValue(group, "groups", i))
+		}
+	}
+	return errs
+}
@@ -19,120 +19,53 @@ package v1alpha1
 import (
 	"github.com/knative/pkg/apis/duck"
 	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
-var _ runtime.Object = (*DriveSource)(nil)
+var _ GSuiteMultiUserSource = (*DriveSource)(nil)
 
 var _ = duck.VerifyType(&DriveSource{}, &duckv1alpha1.Conditions{})
 
 type DriveSourceSpec struct {
-	// TODO be able to set many, so that we create a single Service listening to events from many drives.
-	EmailAddress   string                   `json:"emailAddress"`
-	GcpCredsSecret corev1.SecretKeySelector `json:"gcpCredsSecret"`
-	Sink           *corev1.ObjectReference  `json:"sink"`
+	GSuiteSourceSpec `json:",inline"`
+	GSuiteUsersSpec  `json:",inline"`
+
+	// DriveId is the ID of the shared drive to watch. If not set, the drive of every user is watched.
+	DriveId string `json:"driveId,omitempty"`
+	// AllDrives watches the drive of every user along with all the shared drives they can access.
+	// It cannot be set along with DriveId.
+	AllDrives bool `json:"allDrives,omitempty"`
 }
 
 const (
 	DriveSourceEventType = "org.nachocano.source.gsuite.drive"
-	DriveSourceToken     = DriveSourceEventType
 )
 
 const (
-	DriveSourceConditionReady                                      = duckv1alpha1.ConditionReady
-	DriveSourceConditionSecretsProvided duckv1alpha1.ConditionType = "SecretsProvided"
-	DriveSourceConditionSinkProvided    duckv1alpha1.ConditionType = "SinkProvided"
-	DriveSourceConditionServiceProvided duckv1alpha1.ConditionType = "ServiceProvided"
-	DriveSourceConditionWebHookProvided duckv1alpha1.ConditionType = "WebHookProvided"
-)
-
-var driveSourceCondSet = duckv1alpha1.NewLivingConditionSet(
-	DriveSourceConditionSecretsProvided,
-	DriveSourceConditionSinkProvided,
-	DriveSourceConditionServiceProvided,
-	DriveSourceConditionWebHookProvided,
+	DriveSourceConditionReady           = GSuiteSourceConditionReady
+	DriveSourceConditionSecretsProvided = GSuiteSourceConditionSecretsProvided
+	DriveSourceConditionSinkProvided    = GSuiteSourceConditionSinkProvided
+	DriveSourceConditionServiceProvided = GSuiteSourceConditionServiceProvided
+	DriveSourceConditionWebHookProvided = GSuiteSourceConditionWebHookProvided
 )
 
 type DriveSourceStatus struct {
-	duckv1alpha1.Status `json:",inline"`
-
-	WebhookId         string `json:"webhookId,omitempty"`
-	WebhookResourceId string `json:"webhookResourceId,omitempty"`
-
-	SinkURI string `json:"sinkUri,omitempty"`
-}
-
-// GetCondition returns the condition currently associated with the given type, or nil.
-func (s *DriveSourceStatus) GetCondition(t duckv1alpha1.ConditionType) *duckv1alpha1.Condition {
-	return driveSourceCondSet.Manage(s).GetCondition(t)
-}
-
-// IsReady returns true if the resource is ready overall.
-func (s *DriveSourceStatus) IsReady() bool {
-	return driveSourceCondSet.Manage(s).IsHappy()
-}
-
-// InitializeConditions sets relevant unset conditions to Unknown state.
-func (s *DriveSourceStatus) InitializeConditions() {
-	driveSourceCondSet.Manage(s).InitializeConditions()
-}
-
-// MarkService sets the condition that the source has a service configured.
-func (s *DriveSourceStatus) MarkService() {
-	driveSourceCondSet.Manage(s).MarkTrue(DriveSourceConditionServiceProvided)
-}
-
-// MarkNoService sets the condition that the source does not have a valid service.
-func (s *DriveSourceStatus) MarkNoService(reason, messageFormat string, messageA ...interface{}) {
-	driveSourceCondSet.Manage(s).MarkFalse(DriveSourceConditionServiceProvided, reason, messageFormat, messageA...)
-}
-
-// MarkWebHook sets the condition that the source has a webhook configured.
-func (s *DriveSourceStatus) MarkWebHook(id, resourceId string) {
-	s.WebhookId = id
-	s.WebhookResourceId = resourceId
-	if len(id) > 0 && len(resourceId) > 0 {
-		driveSourceCondSet.Manage(s).MarkTrue(DriveSourceConditionWebHookProvided)
-	} else {
-		driveSourceCondSet.Manage(s).MarkFalse(DriveSourceConditionWebHookProvided,
-			"WebHookParamsEmpty", "WebHookParams empty.")
-	}
-
-}
-
-// MarkNoWebHook sets the condition that the source does not have a valid webhook.
-func (s *DriveSourceStatus) MarkNoWebHook(reason, messageFormat string, messageA ...interface{}) {
-	s.WebhookId = ""
-	s.WebhookResourceId = ""
-	driveSourceCondSet.Manage(s).MarkFalse(DriveSourceConditionWebHookProvided, reason, messageFormat, messageA...)
-}
-
-// MarkSecrets sets the condition that the source has a valid secret.
-func (s *DriveSourceStatus) MarkSecrets() {
-	driveSourceCondSet.Manage(s).MarkTrue(DriveSourceConditionSecretsProvided)
+	GSuiteSourceStatus `json:",inline"`
 }
 
-// MarkNoSecrets sets the condition that the source does not have a valid secret.
-func (s *DriveSourceStatus) MarkNoSecrets(reason, messageFormat string, messageA ...interface{}) {
-	driveSourceCondSet.Manage(s).MarkFalse(DriveSourceConditionSecretsProvided, reason, messageFormat, messageA...)
+// GetGSuiteSpec implements GSuiteSource.
+func (s *DriveSource) GetGSuiteSpec() *GSuiteSourceSpec {
+	return &s.Spec.GSuiteSourceSpec
 }
 
-// MarkSink sets the condition that the source has a sink configured.
-func (s *DriveSourceStatus) MarkSink(uri string) {
-	s.SinkURI = uri
-	if len(uri) > 0 {
-		driveSourceCondSet.Manage(s).MarkTrue(DriveSourceConditionSinkProvided)
-	} else {
-		driveSourceCondSet.Manage(s).MarkUnknown(DriveSourceConditionSinkProvided,
-			"SinkEmpty", "Sink has resolved to empty.")
-	}
+// GetGSuiteStatus implements GSuiteSource.
+func (s *DriveSource) GetGSuiteStatus() *GSuiteSourceStatus {
+	return &s.Status.GSuiteSourceStatus
 }
 
-// MarkNoSink sets the condition that the source does not have a sink configured.
-func (s *DriveSourceStatus) MarkNoSink(reason, messageFormat string, messageA ...interface{}) {
-	driveSourceCondSet.Manage(s).MarkFalse(DriveSourceConditionSinkProvided, reason, messageFormat, messageA...)
+// GetGSuiteUsersSpec implements GSuiteMultiUserSource.
+func (s *DriveSource) GetGSuiteUsersSpec() *GSuiteUsersSpec {
+	return &s.Spec.GSuiteUsersSpec
 }
 
 // +genclient
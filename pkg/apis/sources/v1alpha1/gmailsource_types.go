@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/knative/pkg/apis/duck"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ GSuiteMultiUserSource = (*GmailSource)(nil)
+
+var _ = duck.VerifyType(&GmailSource{}, &duckv1alpha1.Conditions{})
+
+type GmailSourceSpec struct {
+	GSuiteSourceSpec `json:",inline"`
+	GSuiteUsersSpec  `json:",inline"`
+
+	// LabelIds are the IDs of the labels whose messages are watched, e.g., INBOX.
+	// If not set, all the messages of every user are watched.
+	LabelIds []string `json:"labelIds,omitempty"`
+	// Topic is the Pub/Sub topic Gmail publishes the notifications to, i.e., projects/<project>/topics/<topic>.
+	// Gmail must be allowed to publish to it.
+	Topic string `json:"topic"`
+}
+
+const (
+	GmailSourceEventType = "org.nachocano.source.gsuite.gmail"
+
+	// Types of the CloudEvents sent for each mailbox change.
+	GmailSourceMessageAddedEventType   = GmailSourceEventType + ".message.added"
+	GmailSourceMessageDeletedEventType = GmailSourceEventType + ".message.deleted"
+	GmailSourceLabelAddedEventType     = GmailSourceEventType + ".label.added"
+	GmailSourceLabelRemovedEventType   = GmailSourceEventType + ".label.removed"
+)
+
+const (
+	GmailSourceConditionReady           = GSuiteSourceConditionReady
+	GmailSourceConditionSecretsProvided = GSuiteSourceConditionSecretsProvided
+	GmailSourceConditionSinkProvided    = GSuiteSourceConditionSinkProvided
+	GmailSourceConditionServiceProvided = GSuiteSourceConditionServiceProvided
+	GmailSourceConditionWebHookProvided = GSuiteSourceConditionWebHookProvided
+)
+
+type GmailSourceStatus struct {
+	GSuiteSourceStatus `json:",inline"`
+}
+
+// GetGSuiteSpec implements GSuiteSource.
+func (s *GmailSource) GetGSuiteSpec() *GSuiteSourceSpec {
+	return &s.Spec.GSuiteSourceSpec
+}
+
+// GetGSuiteStatus implements GSuiteSource.
+func (s *GmailSource) GetGSuiteStatus() *GSuiteSourceStatus {
+	return &s.Status.GSuiteSourceStatus
+}
+
+// GetGSuiteUsersSpec implements GSuiteMultiUserSource.
+func (s *GmailSource) GetGSuiteUsersSpec() *GSuiteUsersSpec {
+	return &s.Spec.GSuiteUsersSpec
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GmailSource is the Schema for the gmailsources API.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:categories=all,knative,eventing,sources
+type GmailSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GmailSourceSpec   `json:"spec,omitempty"`
+	Status GmailSourceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GmailSourceList contains a list of GmailSource.
+type GmailSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GmailSource `json:"items"`
+}
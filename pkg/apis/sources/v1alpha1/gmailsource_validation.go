@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/knative/pkg/apis"
+)
+
+// topicRegexp matches the full names of the Pub/Sub topics.
+var topicRegexp = regexp.MustCompile(`^projects/[^/]+/topics/[^/]+$`)
+
+// Validate implements apis.Validatable.
+func (s *GmailSource) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.Spec.Validate(ctx).ViaField("spec")
+	if apis.IsInUpdate(ctx) {
+		original := apis.GetBaseline(ctx).(*GmailSource)
+		errs = errs.Also(s.Spec.GSuiteSourceSpec.CheckImmutableFields(ctx, &original.Spec.GSuiteSourceSpec).ViaField("spec"))
+	}
+	return errs
+}
+
+// Validate validates the GmailSource spec.
+func (s *GmailSourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.GSuiteSourceSpec.Validate(ctx).Also(s.GSuiteUsersSpec.Validate(ctx, s.EmailAddress))
+	for i, id := range s.LabelIds {
+		if id == "" {
+			errs = errs.Also(apis.ErrInvalidArrayValue(id, "labelIds", i))
+		}
+	}
+	// The topic is only needed to push the notifications.
+	if s.Topic == "" {
+		if !s.IsPolling() {
+			errs = errs.Also(apis.ErrMissingField("topic"))
+		}
+	} else if !topicRegexp.MatchString(s.Topic) {
+		errs = errs.Also(apis.ErrInvalidValue(s.Topic, "topic"))
+	}
+	return errs
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetDefaults sets the defaults of the spec fields common to all the G Suite sources.
+// The sources in poll mode read the changes every minute by default, and all the sources
+// send CloudEvents 1.0 in binary mode, retrying the failed deliveries three times, by default.
+func (s *GSuiteSourceSpec) SetDefaults(ctx context.Context) {
+	if s.IsPolling() && s.PollInterval == nil {
+		s.PollInterval = &metav1.Duration{Duration: DefaultPollInterval}
+	}
+	ce := s.GetCloudEvents()
+	s.CloudEvents = &ce
+	delivery := s.GetDelivery()
+	s.Delivery = &delivery
+}
+
+// SetDefaults sets the defaults of the users to watch. The G Suite admin to impersonate
+// when listing the members of the group defaults to the given email address.
+func (s *GSuiteUsersSpec) SetDefaults(ctx context.Context, emailAddress string) {
+	if s.Group != "" && s.AdminEmailAddress == "" {
+		s.AdminEmailAddress = emailAddress
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGSuiteSourceSetDefaults(t *testing.T) {
+	retries := int32(DefaultDeliveryRetries)
+	defaultDelivery := &DeliverySpec{
+		Retries:      &retries,
+		BackoffDelay: &metav1.Duration{Duration: DefaultDeliveryBackoffDelay},
+	}
+	defaultCloudEvents := &CloudEventsSpec{SpecVersion: DefaultCloudEventsSpecVersion, Mode: CloudEventsModeBinary}
+
+	tests := []struct {
+		name   string
+		mutate func(s *DriveSource)
+		want   func(s *DriveSource)
+	}{{
+		name:   "push mode",
+		mutate: func(s *DriveSource) {},
+		want: func(s *DriveSource) {
+			s.Spec.CloudEvents = defaultCloudEvents
+			s.Spec.Delivery = defaultDelivery
+		},
+	}, {
+		name: "poll mode",
+		mutate: func(s *DriveSource) {
+			s.Spec.Mode = GSuiteSourceModePoll
+		},
+		want: func(s *DriveSource) {
+			s.Spec.Mode = GSuiteSourceModePoll
+			s.Spec.PollInterval = &metav1.Duration{Duration: DefaultPollInterval}
+			s.Spec.CloudEvents = defaultCloudEvents
+			s.Spec.Delivery = defaultDelivery
+		},
+	}, {
+		name: "group admin",
+		mutate: func(s *DriveSource) {
+			s.Spec.Group = "group@example.com"
+		},
+		want: func(s *DriveSource) {
+			s.Spec.Group = "group@example.com"
+			s.Spec.AdminEmailAddress = "user@example.com"
+			s.Spec.CloudEvents = defaultCloudEvents
+			s.Spec.Delivery = defaultDelivery
+		},
+	}, {
+		name: "set fields are kept",
+		mutate: func(s *DriveSource) {
+			s.Spec.Mode = GSuiteSourceModePoll
+			s.Spec.PollInterval = &metav1.Duration{Duration: time.Hour}
+			s.Spec.CloudEvents = &CloudEventsSpec{SpecVersion: "0.3", Mode: CloudEventsModeStructured}
+			s.Spec.Delivery = &DeliverySpec{BackoffDelay: &metav1.Duration{Duration: time.Minute}}
+		},
+		want: func(s *DriveSource) {
+			s.Spec.Mode = GSuiteSourceModePoll
+			s.Spec.PollInterval = &metav1.Duration{Duration: time.Hour}
+			s.Spec.CloudEvents = &CloudEventsSpec{SpecVersion: "0.3", Mode: CloudEventsModeStructured}
+			s.Spec.Delivery = &DeliverySpec{Retries: &retries, BackoffDelay: &metav1.Duration{Duration: time.Minute}}
+		},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newDriveSource()
+			tc.mutate(got)
+			got.SetDefaults(context.Background())
+			want := newDriveSource()
+			tc.want(want)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("SetDefaults() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// GSuiteSource is implemented by all the G Suite sources, so that they can be
+// reconciled by the same controller.
+// +k8s:deepcopy-gen=false
+type GSuiteSource interface {
+	runtime.Object
+	metav1.Object
+
+	// GetGSuiteSpec returns the spec fields common to all the G Suite sources.
+	GetGSuiteSpec() *GSuiteSourceSpec
+	// GetGSuiteStatus returns the status fields common to all the G Suite sources.
+	GetGSuiteStatus() *GSuiteSourceStatus
+}
+
+// GSuiteMultiUserSource is implemented by the G Suite sources that can watch many users.
+// +k8s:deepcopy-gen=false
+type GSuiteMultiUserSource interface {
+	GSuiteSource
+
+	// GetGSuiteUsersSpec returns the users to watch, in addition to the spec EmailAddress.
+	GetGSuiteUsersSpec() *GSuiteUsersSpec
+}
+
+// GSuiteSourceSpec are the spec fields common to all the G Suite sources.
+type GSuiteSourceSpec struct {
+	EmailAddress   string                   `json:"emailAddress,omitempty"`
+	GcpCredsSecret corev1.SecretKeySelector `json:"gcpCredsSecret"`
+	Sink           *corev1.ObjectReference  `json:"sink"`
+	// DeadLetterSink is the object that receives the events that could not be delivered to the sink, if any.
+	DeadLetterSink *corev1.ObjectReference `json:"deadLetterSink,omitempty"`
+	// Delivery is how the failed deliveries to the sink are retried.
+	Delivery *DeliverySpec `json:"delivery,omitempty"`
+
+	// Mode is how the changes are received, i.e., push, the default, or poll. In poll mode, no channel is
+	// created, and the receive adapter reads the changes every PollInterval, so that the source does not
+	// need a publicly reachable HTTPS domain.
+	Mode GSuiteSourceMode `json:"mode,omitempty"`
+	// PollInterval is how often the changes are read in poll mode. It defaults to one minute.
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+
+	// CloudEvents is how the CloudEvents are sent to the sink. It defaults to CloudEvents 1.0 in binary mode.
+	CloudEvents *CloudEventsSpec `json:"cloudEvents,omitempty"`
+}
+
+// DeliverySpec is how a G Suite source retries the failed deliveries to its sink, with exponential backoff.
+type DeliverySpec struct {
+	// Retries is the number of times a failed delivery is retried.
+	Retries *int32 `json:"retries,omitempty"`
+	// BackoffDelay is the delay before the first retry, which is doubled on every following retry.
+	BackoffDelay *metav1.Duration `json:"backoffDelay,omitempty"`
+}
+
+const (
+	// DefaultDeliveryRetries is the number of retries of the sources that do not set one.
+	DefaultDeliveryRetries = 3
+	// DefaultDeliveryBackoffDelay is the delay before the first retry of the sources that do not set one.
+	DefaultDeliveryBackoffDelay = time.Second
+)
+
+// GetDelivery returns how the failed deliveries are retried, with the defaults of the unset fields.
+func (s *GSuiteSourceSpec) GetDelivery() DeliverySpec {
+	retries := int32(DefaultDeliveryRetries)
+	delivery := DeliverySpec{
+		Retries:      &retries,
+		BackoffDelay: &metav1.Duration{Duration: DefaultDeliveryBackoffDelay},
+	}
+	if s.Delivery != nil {
+		if s.Delivery.Retries != nil {
+			retries = *s.Delivery.Retries
+		}
+		if s.Delivery.BackoffDelay != nil {
+			delivery.BackoffDelay = s.Delivery.BackoffDelay.DeepCopy()
+		}
+	}
+	return delivery
+}
+
+// CloudEventsSpec is how a G Suite source sends the CloudEvents to its sink.
+type CloudEventsSpec struct {
+	// SpecVersion is the version of the CloudEvents spec, i.e., 0.2, 0.3 or 1.0.
+	SpecVersion string `json:"specVersion,omitempty"`
+	// Mode is the HTTP content mode, i.e., binary or structured.
+	Mode CloudEventsMode `json:"mode,omitempty"`
+}
+
+// CloudEventsMode is the HTTP content mode of the CloudEvents.
+type CloudEventsMode string
+
+const (
+	// CloudEventsModeBinary sends the attributes of the CloudEvents as HTTP headers, and their data as the body.
+	CloudEventsModeBinary CloudEventsMode = "binary"
+	// CloudEventsModeStructured sends the whole CloudEvents as JSON bodies.
+	CloudEventsModeStructured CloudEventsMode = "structured"
+
+	// DefaultCloudEventsSpecVersion is the version of the CloudEvents spec of the sources that do not set one.
+	DefaultCloudEventsSpecVersion = "1.0"
+)
+
+// CloudEventsSpecVersions are the supported versions of the CloudEvents spec.
+var CloudEventsSpecVersions = []string{"0.2", "0.3", "1.0"}
+
+// GetCloudEvents returns how the CloudEvents are sent to the sink, with the defaults of the unset fields.
+func (s *GSuiteSourceSpec) GetCloudEvents() CloudEventsSpec {
+	ce := CloudEventsSpec{
+		SpecVersion: DefaultCloudEventsSpecVersion,
+		Mode:        CloudEventsModeBinary,
+	}
+	if s.CloudEvents != nil {
+		if s.CloudEvents.SpecVersion != "" {
+			ce.SpecVersion = s.CloudEvents.SpecVersion
+		}
+		if s.CloudEvents.Mode != "" {
+			ce.Mode = s.CloudEvents.Mode
+		}
+	}
+	return ce
+}
+
+// GSuiteSourceMode is how a G Suite source receives the changes.
+type GSuiteSourceMode string
+
+const (
+	// GSuiteSourceModePush watches the changes with push notification channels.
+	GSuiteSourceModePush GSuiteSourceMode = "push"
+	// GSuiteSourceModePoll periodically reads the changes from the stored cursors.
+	GSuiteSourceModePoll GSuiteSourceMode = "poll"
+
+	// DefaultPollInterval is the poll interval of the sources in poll mode that do not set one.
+	DefaultPollInterval = time.Minute
+	// MinPollInterval is the minimum poll interval, so that the sources do not exhaust the API quotas.
+	MinPollInterval = 10 * time.Second
+)
+
+// IsPolling returns true if the source is in poll mode.
+func (s *GSuiteSourceSpec) IsPolling() bool {
+	return s.Mode == GSuiteSourceModePoll
+}
+
+// GSuiteUsersSpec are the spec fields of the G Suite sources that can watch many users.
+type GSuiteUsersSpec struct {
+	// EmailAddresses are the users to watch.
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+	// Group is the email address of a Google group whose members are watched.
+	Group string `json:"group,omitempty"`
+	// AdminEmailAddress is the G Suite admin to impersonate when listing the members of Group.
+	AdminEmailAddress string `json:"adminEmailAddress,omitempty"`
+}
+
+const (
+	GSuiteSourceConditionReady                                      = duckv1alpha1.ConditionReady
+	GSuiteSourceConditionSecretsProvided duckv1alpha1.ConditionType = "SecretsProvided"
+	GSuiteSourceConditionSinkProvided    duckv1alpha1.ConditionType = "SinkProvided"
+	GSuiteSourceConditionServiceProvided duckv1alpha1.ConditionType = "ServiceProvided"
+	GSuiteSourceConditionWebHookProvided duckv1alpha1.ConditionType = "WebHookProvided"
+	// GSuiteSourceConditionPollingProvided replaces GSuiteSourceConditionWebHookProvided in poll mode.
+	GSuiteSourceConditionPollingProvided duckv1alpha1.ConditionType = "PollingProvided"
+	// GSuiteSourceConditionFinalizing reports the progress of stopping the webhooks of a deleted source.
+	// It does not affect the readiness of the source.
+	GSuiteSourceConditionFinalizing duckv1alpha1.ConditionType = "Finalizing"
+)
+
+const (
+	// SkipChannelStopAnnotation, set to "true", deletes the source without stopping its webhooks,
+	// e.g., when they cannot be stopped anymore. They keep sending notifications until they expire.
+	SkipChannelStopAnnotation = "gsuite.nachocano.org/skip-channel-stop"
+)
+
+var gSuiteSourceCondSet = duckv1alpha1.NewLivingConditionSet(
+	GSuiteSourceConditionSecretsProvided,
+	GSuiteSourceConditionSinkProvided,
+	GSuiteSourceConditionServiceProvided,
+	GSuiteSourceConditionWebHookProvided,
+)
+
+var gSuiteSourcePollCondSet = duckv1alpha1.NewLivingConditionSet(
+	GSuiteSourceConditionSecretsProvided,
+	GSuiteSourceConditionSinkProvided,
+	GSuiteSourceConditionServiceProvided,
+	GSuiteSourceConditionPollingProvided,
+)
+
+// GSuiteSourceStatus are the status fields common to all the G Suite sources.
+type GSuiteSourceStatus struct {
+	duckv1alpha1.Status `json:",inline"`
+
+	// Webhooks are the webhooks of the source, one per watched user and resource.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+
+	SinkURI string `json:"sinkUri,omitempty"`
+	// DeadLetterSinkURI is the URI of the dead letter sink, if any.
+	DeadLetterSinkURI string `json:"deadLetterSinkUri,omitempty"`
+}
+
+// Webhook is the push notification channel watching the changes of a resource of a user.
+type Webhook struct {
+	// EmailAddress is the watched user. It is empty if no user is impersonated.
+	EmailAddress string `json:"emailAddress,omitempty"`
+	// Resource is the watched resource of the user, e.g., a calendar ID.
+	// It is empty for the kinds that watch a single resource per user.
+	Resource string `json:"resource,omitempty"`
+	// Cursor is the position from where the receive adapter starts reading the changes
+	// of the resource when it is first watched, e.g., the Calendar Events sync token.
+	// The receive adapter then saves its progress in the cursors ConfigMap of the source.
+	Cursor string `json:"cursor,omitempty"`
+
+	Id         string `json:"id,omitempty"`
+	ResourceId string `json:"resourceId,omitempty"`
+	// Expiration is the time at which the webhook expires. It is renewed before then.
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+	// Token is the key of the webhook token in the channel tokens secret of the source.
+	Token string `json:"token,omitempty"`
+	// Domain is the domain of the receive adapter the webhook sends its notifications to.
+	// The webhook is created again when the domain changes.
+	Domain string `json:"domain,omitempty"`
+	// WatchParams are the fields of the source, besides the user and the resource, the webhook
+	// was created with, e.g., the Gmail Pub/Sub topic. The webhook is created again when they change.
+	WatchParams string `json:"watchParams,omitempty"`
+	// Polled is true if the receive adapter polls the resource instead of being notified of its changes,
+	// e.g., the members of a Directory group. Its webhook is then not a push notification channel.
+	Polled bool `json:"polled,omitempty"`
+}
+
+// GetWebhook returns the webhook of the given resource of the given user, or nil.
+func (s *GSuiteSourceStatus) GetWebhook(emailAddress, resource string) *Webhook {
+	for i := range s.Webhooks {
+		if s.Webhooks[i].EmailAddress == emailAddress && s.Webhooks[i].Resource == resource {
+			return &s.Webhooks[i]
+		}
+	}
+	return nil
+}
+
+// condSet returns the conditions of the source, which has a PollingProvided condition
+// instead of the WebHookProvided one in poll mode.
+func (s *GSuiteSourceStatus) condSet() duckv1alpha1.ConditionSet {
+	if s.Status.GetCondition(GSuiteSourceConditionPollingProvided) != nil {
+		return gSuiteSourcePollCondSet
+	}
+	return gSuiteSourceCondSet
+}
+
+// GetCondition returns the condition currently associated with the given type, or nil.
+func (s *GSuiteSourceStatus) GetCondition(t duckv1alpha1.ConditionType) *duckv1alpha1.Condition {
+	return s.condSet().Manage(s).GetCondition(t)
+}
+
+// IsReady returns true if the resource is ready overall.
+func (s *GSuiteSourceStatus) IsReady() bool {
+	return s.condSet().Manage(s).IsHappy()
+}
+
+// InitializeConditions sets relevant unset conditions to Unknown state. When the source switches
+// between push and poll mode, the condition of the previous mode is removed.
+func (s *GSuiteSourceStatus) InitializeConditions(polling bool) {
+	mode, condSet := GSuiteSourceModePush, gSuiteSourceCondSet
+	current, stale := GSuiteSourceConditionWebHookProvided, GSuiteSourceConditionPollingProvided
+	if polling {
+		mode, condSet = GSuiteSourceModePoll, gSuiteSourcePollCondSet
+		current, stale = GSuiteSourceConditionPollingProvided, GSuiteSourceConditionWebHookProvided
+	}
+	if s.Status.GetCondition(stale) == nil {
+		condSet.Manage(s).InitializeConditions()
+		return
+	}
+	conditions := make(duckv1alpha1.Conditions, 0, len(s.Conditions))
+	for _, c := range s.Conditions {
+		if c.Type != stale {
+			conditions = append(conditions, c)
+		}
+	}
+	s.Conditions = conditions
+	condSet.Manage(s).InitializeConditions()
+	condSet.Manage(s).MarkUnknown(current, "ModeChanged", "The source switched to %s mode.", mode)
+}
+
+// MarkService sets the condition that the source has a service configured.
+func (s *GSuiteSourceStatus) MarkService() {
+	s.condSet().Manage(s).MarkTrue(GSuiteSourceConditionServiceProvided)
+}
+
+// MarkNoService sets the condition that the source does not have a valid service.
+func (s *GSuiteSourceStatus) MarkNoService(reason, messageFormat string, messageA ...interface{}) {
+	s.condSet().Manage(s).MarkFalse(GSuiteSourceConditionServiceProvided, reason, messageFormat, messageA...)
+}
+
+// MarkWebHook sets the condition that the source has a webhook configured for every user.
+func (s *GSuiteSourceStatus) MarkWebHook() {
+	for _, webhook := range s.Webhooks {
+		if len(webhook.Id) == 0 || len(webhook.ResourceId) == 0 {
+			gSuiteSourceCondSet.Manage(s).MarkFalse(GSuiteSourceConditionWebHookProvided,
+				"WebHookParamsEmpty", "WebHookParams empty for user %q resource %q.", webhook.EmailAddress, webhook.Resource)
+			return
+		}
+	}
+	if len(s.Webhooks) == 0 {
+		gSuiteSourceCondSet.Manage(s).MarkFalse(GSuiteSourceConditionWebHookProvided,
+			"WebHookParamsEmpty", "WebHookParams empty.")
+		return
+	}
+	gSuiteSourceCondSet.Manage(s).MarkTrue(GSuiteSourceConditionWebHookProvided)
+}
+
+// MarkNoWebHook sets the condition that the source does not have a valid webhook.
+func (s *GSuiteSourceStatus) MarkNoWebHook(reason, messageFormat string, messageA ...interface{}) {
+	gSuiteSourceCondSet.Manage(s).MarkFalse(GSuiteSourceConditionWebHookProvided, reason, messageFormat, messageA...)
+}
+
+// MarkNoWebHookRenewal sets the condition that the source could not renew its webhook.
+// The current webhook is kept, as it is still valid until it expires.
+func (s *GSuiteSourceStatus) MarkNoWebHookRenewal(reason, messageFormat string, messageA ...interface{}) {
+	gSuiteSourceCondSet.Manage(s).MarkFalse(GSuiteSourceConditionWebHookProvided, reason, messageFormat, messageA...)
+}
+
+// MarkPolling sets the condition that the receive adapter polls the changes of every resource.
+func (s *GSuiteSourceStatus) MarkPolling() {
+	if len(s.Webhooks) == 0 {
+		gSuiteSourcePollCondSet.Manage(s).MarkFalse(GSuiteSourceConditionPollingProvided,
+			"PollingParamsEmpty", "Polling params empty.")
+		return
+	}
+	gSuiteSourcePollCondSet.Manage(s).MarkTrue(GSuiteSourceConditionPollingProvided)
+}
+
+// MarkNoPolling sets the condition that the receive adapter cannot poll the changes.
+func (s *GSuiteSourceStatus) MarkNoPolling(reason, messageFormat string, messageA ...interface{}) {
+	gSuiteSourcePollCondSet.Manage(s).MarkFalse(GSuiteSourceConditionPollingProvided, reason, messageFormat, messageA...)
+}
+
+// MarkSecrets sets the condition that the source has a valid secret.
+func (s *GSuiteSourceStatus) MarkSecrets() {
+	s.condSet().Manage(s).MarkTrue(GSuiteSourceConditionSecretsProvided)
+}
+
+// MarkNoSecrets sets the condition that the source does not have a valid secret.
+func (s *GSuiteSourceStatus) MarkNoSecrets(reason, messageFormat string, messageA ...interface{}) {
+	s.condSet().Manage(s).MarkFalse(GSuiteSourceConditionSecretsProvided, reason, messageFormat, messageA...)
+}
+
+// MarkSink sets the condition that the source has a sink configured.
+func (s *GSuiteSourceStatus) MarkSink(uri string) {
+	s.SinkURI = uri
+	if len(uri) > 0 {
+		s.condSet().Manage(s).MarkTrue(GSuiteSourceConditionSinkProvided)
+	} else {
+		s.condSet().Manage(s).MarkUnknown(GSuiteSourceConditionSinkProvided,
+			"SinkEmpty", "Sink has resolved to empty.")
+	}
+}
+
+// MarkNoSink sets the condition that the source does not have a sink configured.
+func (s *GSuiteSourceStatus) MarkNoSink(reason, messageFormat string, messageA ...interface{}) {
+	s.condSet().Manage(s).MarkFalse(GSuiteSourceConditionSinkProvided, reason, messageFormat, messageA...)
+}
+
+// MarkFinalizing sets the condition that the webhooks of the deleted source are still being stopped,
+// e.g., after a transient error.
+func (s *GSuiteSourceStatus) MarkFinalizing(reason, messageFormat string, messageA ...interface{}) {
+	s.setCondition(GSuiteSourceConditionFinalizing, corev1.ConditionUnknown, reason, fmt.Sprintf(messageFormat, messageA...))
+}
+
+// MarkNoFinalizing sets the condition that the webhooks of the deleted source cannot be stopped
+// without intervention, e.g., a fix of the permissions of the service account.
+func (s *GSuiteSourceStatus) MarkNoFinalizing(reason, messageFormat string, messageA ...interface{}) {
+	s.setCondition(GSuiteSourceConditionFinalizing, corev1.ConditionFalse, reason, fmt.Sprintf(messageFormat, messageA...))
+}
+
+// MarkFinalized sets the condition that the webhooks of the deleted source are stopped, or expired.
+func (s *GSuiteSourceStatus) MarkFinalized() {
+	s.setCondition(GSuiteSourceConditionFinalizing, corev1.ConditionTrue, "", "")
+}
+
+// setCondition sets a condition that does not affect the readiness of the source, leaving the Ready one as is.
+func (s *GSuiteSourceStatus) setCondition(t duckv1alpha1.ConditionType, status corev1.ConditionStatus, reason, message string) {
+	s.condSet().Manage(s).SetCondition(duckv1alpha1.Condition{
+		Type:     t,
+		Status:   status,
+		Reason:   reason,
+		Message:  message,
+		Severity: duckv1alpha1.ConditionSeverityInfo,
+	})
+}
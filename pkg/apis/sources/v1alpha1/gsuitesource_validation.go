@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/knative/pkg/apis"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// Validate validates the spec fields common to all the G Suite sources.
+func (s *GSuiteSourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if s.EmailAddress != "" && !isEmailAddress(s.EmailAddress) {
+		errs = errs.Also(apis.ErrInvalidValue(s.EmailAddress, "emailAddress"))
+	}
+	if s.GcpCredsSecret.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("gcpCredsSecret.name"))
+	}
+	if s.GcpCredsSecret.Key == "" {
+		errs = errs.Also(apis.ErrMissingField("gcpCredsSecret.key"))
+	}
+	if s.Sink == nil {
+		errs = errs.Also(apis.ErrMissingField("sink"))
+	} else {
+		errs = errs.Also(validateSink(s.Sink).ViaField("sink"))
+	}
+	if s.DeadLetterSink != nil {
+		errs = errs.Also(validateSink(s.DeadLetterSink).ViaField("deadLetterSink"))
+	}
+	if s.Delivery != nil {
+		errs = errs.Also(s.Delivery.Validate(ctx).ViaField("delivery"))
+	}
+	switch s.Mode {
+	case "", GSuiteSourceModePush:
+		if s.PollInterval != nil {
+			errs = errs.Also(apis.ErrDisallowedFields("pollInterval"))
+		}
+	case GSuiteSourceModePoll:
+		if s.PollInterval != nil && s.PollInterval.Duration < MinPollInterval {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Poll interval shorter than %s", MinPollInterval),
+				Paths:   []string{"pollInterval"},
+			})
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(string(s.Mode), "mode"))
+	}
+	if s.CloudEvents != nil {
+		errs = errs.Also(s.CloudEvents.Validate(ctx).ViaField("cloudEvents"))
+	}
+	return errs
+}
+
+// validateSink validates the reference to a sink.
+func validateSink(sink *corev1.ObjectReference) *apis.FieldError {
+	var errs *apis.FieldError
+	if sink.APIVersion == "" {
+		errs = errs.Also(apis.ErrMissingField("apiVersion"))
+	}
+	if sink.Kind == "" {
+		errs = errs.Also(apis.ErrMissingField("kind"))
+	}
+	if sink.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("name"))
+	}
+	return errs
+}
+
+// Validate validates the retries and the backoff delay, if set.
+func (s *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if s.Retries != nil && *s.Retries < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprint(*s.Retries), "retries"))
+	}
+	if s.BackoffDelay != nil && s.BackoffDelay.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(s.BackoffDelay.Duration.String(), "backoffDelay"))
+	}
+	return errs
+}
+
+// Validate validates the CloudEvents spec version and HTTP content mode, if set.
+func (s *CloudEventsSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if s.SpecVersion != "" {
+		valid := false
+		for _, v := range CloudEventsSpecVersions {
+			valid = valid || s.SpecVersion == v
+		}
+		if !valid {
+			errs = errs.Also(apis.ErrInvalidValue(s.SpecVersion, "specVersion"))
+		}
+	}
+	switch s.Mode {
+	case "", CloudEventsModeBinary, CloudEventsModeStructured:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(string(s.Mode), "mode"))
+	}
+	return errs
+}
+
+// validatePushMode checks that the source is not in poll mode, for the kinds whose changes
+// are carried by the push notifications themselves, and thus cannot be polled.
+func (s *GSuiteSourceSpec) validatePushMode() *apis.FieldError {
+	if s.IsPolling() {
+		return &apis.FieldError{
+			Message: "Poll mode not supported",
+			Paths:   []string{"mode"},
+		}
+	}
+	return nil
+}
+
+// CheckImmutableFields checks that the immutable spec fields common to all the G Suite sources did not change.
+// The credentials cannot change, as the existing webhooks can only be stopped by the service account that created them.
+func (s *GSuiteSourceSpec) CheckImmutableFields(ctx context.Context, original *GSuiteSourceSpec) *apis.FieldError {
+	if original == nil {
+		return nil
+	}
+	if !equality.Semantic.DeepEqual(s.GcpCredsSecret, original.GcpCredsSecret) {
+		return &apis.FieldError{
+			Message: "Immutable field changed",
+			Paths:   []string{"gcpCredsSecret"},
+		}
+	}
+	return nil
+}
+
+// Validate validates the users to watch. At least one must be set, either in the users spec
+// or in the given email address.
+func (s *GSuiteUsersSpec) Validate(ctx context.Context, emailAddress string) *apis.FieldError {
+	var errs *apis.FieldError
+	if emailAddress == "" && len(s.EmailAddresses) == 0 && s.Group == "" {
+		errs = errs.Also(apis.ErrMissingOneOf("emailAddress", "emailAddresses", "group"))
+	}
+	for i, e := range s.EmailAddresses {
+		if !isEmailAddress(e) {
+			errs = errs.Also(apis.ErrInvalidArrayValue(e, "emailAddresses", i))
+		}
+	}
+	if s.Group != "" {
+		if !isEmailAddress(s.Group) {
+			errs = errs.Also(apis.ErrInvalidValue(s.Group, "group"))
+		}
+		if s.AdminEmailAddress == "" {
+			errs = errs.Also(apis.ErrMissingField("adminEmailAddress"))
+		}
+	}
+	if s.AdminEmailAddress != "" && !isEmailAddress(s.AdminEmailAddress) {
+		errs = errs.Also(apis.ErrInvalidValue(s.AdminEmailAddress, "adminEmailAddress"))
+	}
+	return errs
+}
+
+// isEmailAddress returns true if the given string is a bare email address, e.g., user@example.com.
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knative/pkg/apis"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newDriveSource returns a valid DriveSource of a single user.
+func newDriveSource() *DriveSource {
+	return &DriveSource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "drive-source"},
+		Spec: DriveSourceSpec{
+			GSuiteSourceSpec: GSuiteSourceSpec{
+				EmailAddress: "user@example.com",
+				GcpCredsSecret: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "gcp-creds"},
+					Key:                  "key.json",
+				},
+				Sink: &corev1.ObjectReference{
+					APIVersion: "eventing.knative.dev/v1alpha1",
+					Kind:       "Channel",
+					Name:       "sink",
+				},
+			},
+		},
+	}
+}
+
+func TestGSuiteSourceValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(s *DriveSource)
+		// wantPaths are the paths of the fields of the error, if any.
+		wantPaths []string
+	}{{
+		name:   "valid",
+		mutate: func(s *DriveSource) {},
+	}, {
+		name: "invalid email",
+		mutate: func(s *DriveSource) {
+			s.Spec.EmailAddress = "User <user@example.com>"
+		},
+		wantPaths: []string{"spec.emailAddress"},
+	}, {
+		name: "invalid email in the users",
+		mutate: func(s *DriveSource) {
+			s.Spec.EmailAddresses = []string{"user@example.com", "not an email"}
+		},
+		wantPaths: []string{"spec.emailAddresses[1]"},
+	}, {
+		name: "no users",
+		mutate: func(s *DriveSource) {
+			s.Spec.EmailAddress = ""
+		},
+		wantPaths: []string{"spec.emailAddress", "spec.emailAddresses", "spec.group"},
+	}, {
+		name: "empty secret key",
+		mutate: func(s *DriveSource) {
+			s.Spec.GcpCredsSecret.Key = ""
+		},
+		wantPaths: []string{"spec.gcpCredsSecret.key"},
+	}, {
+		name: "missing sink",
+		mutate: func(s *DriveSource) {
+			s.Spec.Sink = nil
+		},
+		wantPaths: []string{"spec.sink"},
+	}, {
+		name: "sink without apiVersion and kind",
+		mutate: func(s *DriveSource) {
+			s.Spec.Sink = &corev1.ObjectReference{Name: "sink"}
+		},
+		wantPaths: []string{"spec.sink.apiVersion", "spec.sink.kind"},
+	}, {
+		name: "dead letter sink without name",
+		mutate: func(s *DriveSource) {
+			s.Spec.DeadLetterSink = &corev1.ObjectReference{APIVersion: "v1", Kind: "Service"}
+		},
+		wantPaths: []string{"spec.deadLetterSink.name"},
+	}, {
+		name: "negative retries",
+		mutate: func(s *DriveSource) {
+			retries := int32(-1)
+			s.Spec.Delivery = &DeliverySpec{Retries: &retries}
+		},
+		wantPaths: []string{"spec.delivery.retries"},
+	}, {
+		name: "poll interval in push mode",
+		mutate: func(s *DriveSource) {
+			s.Spec.PollInterval = &metav1.Duration{Duration: time.Minute}
+		},
+		wantPaths: []string{"spec.pollInterval"},
+	}, {
+		name: "short poll interval",
+		mutate: func(s *DriveSource) {
+			s.Spec.Mode = GSuiteSourceModePoll
+			s.Spec.PollInterval = &metav1.Duration{Duration: time.Second}
+		},
+		wantPaths: []string{"spec.pollInterval"},
+	}, {
+		name: "unsupported CloudEvents version",
+		mutate: func(s *DriveSource) {
+			s.Spec.CloudEvents = &CloudEventsSpec{SpecVersion: "0.1"}
+		},
+		wantPaths: []string{"spec.cloudEvents.specVersion"},
+	}, {
+		name: "group without admin",
+		mutate: func(s *DriveSource) {
+			s.Spec.EmailAddress = ""
+			s.Spec.Group = "group@example.com"
+		},
+		wantPaths: []string{"spec.adminEmailAddress"},
+	}, {
+		name: "shared drive",
+		mutate: func(s *DriveSource) {
+			s.Spec.DriveId = "drive"
+		},
+	}, {
+		name: "shared drive and all drives",
+		mutate: func(s *DriveSource) {
+			s.Spec.DriveId = "drive"
+			s.Spec.AllDrives = true
+		},
+		wantPaths: []string{"spec.driveId", "spec.allDrives"},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newDriveSource()
+			tc.mutate(s)
+			checkFieldError(t, s.Validate(apis.WithinCreate(context.Background())), tc.wantPaths)
+		})
+	}
+}
+
+func TestGSuiteSourceCheckImmutableFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(s *DriveSource)
+		wantPaths []string
+	}{{
+		name: "changed user",
+		mutate: func(s *DriveSource) {
+			s.Spec.EmailAddress = "other@example.com"
+		},
+	}, {
+		name: "changed gcpCredsSecret name",
+		mutate: func(s *DriveSource) {
+			s.Spec.GcpCredsSecret.Name = "other-creds"
+		},
+		wantPaths: []string{"spec.gcpCredsSecret"},
+	}, {
+		name: "changed gcpCredsSecret key",
+		mutate: func(s *DriveSource) {
+			s.Spec.GcpCredsSecret.Key = "other.json"
+		},
+		wantPaths: []string{"spec.gcpCredsSecret"},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			original := newDriveSource()
+			s := newDriveSource()
+			tc.mutate(s)
+			checkFieldError(t, s.Validate(apis.WithinUpdate(context.Background(), original)), tc.wantPaths)
+		})
+	}
+}
+
+// checkFieldError checks that the error is about the given fields, or nil if there are none.
+func checkFieldError(t *testing.T, err *apis.FieldError, wantPaths []string) {
+	t.Helper()
+	if len(wantPaths) == 0 {
+		if err != nil {
+			t.Errorf("Validate() = %v, want nil", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("Validate() = nil, want an error about %v", wantPaths)
+	}
+	for _, path := range wantPaths {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("Validate() = %v, want an error about %s", err, path)
+		}
+	}
+}
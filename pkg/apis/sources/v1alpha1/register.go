@@ -31,8 +31,16 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CalendarSource{},
 		&CalendarSourceList{},
+		&DirectorySource{},
+		&DirectorySourceList{},
 		&DriveSource{},
 		&DriveSourceList{},
+		&GmailSource{},
+		&GmailSourceList{},
+		&ReportsSource{},
+		&ReportsSourceList{},
+		&SheetsSource{},
+		&SheetsSourceList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/knative/pkg/apis/duck"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ GSuiteSource = (*ReportsSource)(nil)
+
+var _ = duck.VerifyType(&ReportsSource{}, &duckv1alpha1.Conditions{})
+
+// ReportsSourceSpec watches the activities of the G Suite domain. Its EmailAddress is the
+// G Suite admin to impersonate, and must be set.
+type ReportsSourceSpec struct {
+	GSuiteSourceSpec `json:",inline"`
+
+	// Applications are the applications whose activities are watched, e.g., login, drive, token, admin or groups.
+	Applications []string `json:"applications"`
+	// UserKey is the email address or the profile ID of the user whose activities are watched.
+	// It defaults to all, to watch the activities of all the users.
+	UserKey string `json:"userKey,omitempty"`
+}
+
+const (
+	// ReportsSourceEventType is the prefix of the types of the CloudEvents sent for each activity event,
+	// i.e., org.nachocano.source.gsuite.reports.<application>.<event name>.
+	ReportsSourceEventType = "org.nachocano.source.gsuite.reports"
+
+	// ReportsSourceAllUsers is the user key to watch the activities of all the users.
+	ReportsSourceAllUsers = "all"
+)
+
+const (
+	ReportsSourceConditionReady           = GSuiteSourceConditionReady
+	ReportsSourceConditionSecretsProvided = GSuiteSourceConditionSecretsProvided
+	ReportsSourceConditionSinkProvided    = GSuiteSourceConditionSinkProvided
+	ReportsSourceConditionServiceProvided = GSuiteSourceConditionServiceProvided
+	ReportsSourceConditionWebHookProvided = GSuiteSourceConditionWebHookProvided
+)
+
+type ReportsSourceStatus struct {
+	GSuiteSourceStatus `json:",inline"`
+}
+
+// GetGSuiteSpec implements GSuiteSource.
+func (s *ReportsSource) GetGSuiteSpec() *GSuiteSourceSpec {
+	return &s.Spec.GSuiteSourceSpec
+}
+
+// GetGSuiteStatus implements GSuiteSource.
+func (s *ReportsSource) GetGSuiteStatus() *GSuiteSourceStatus {
+	return &s.Status.GSuiteSourceStatus
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ReportsSource is the Schema for the reportssources API.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:categories=all,knative,eventing,sources
+type ReportsSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReportsSourceSpec   `json:"spec,omitempty"`
+	Status ReportsSourceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ReportsSourceList contains a list of ReportsSource.
+type ReportsSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReportsSource `json:"items"`
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/knative/pkg/apis"
+)
+
+// applicationRegexp matches the names of the Reports API applications, e.g., login or user_accounts.
+var applicationRegexp = regexp.MustCompile(`^[a-z][a-z_]*$`)
+
+// Validate implements apis.Validatable.
+func (s *ReportsSource) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.Spec.Validate(ctx).ViaField("spec")
+	if apis.IsInUpdate(ctx) {
+		original := apis.GetBaseline(ctx).(*ReportsSource)
+		errs = errs.Also(s.Spec.GSuiteSourceSpec.CheckImmutableFields(ctx, &original.Spec.GSuiteSourceSpec).ViaField("spec"))
+	}
+	return errs
+}
+
+// Validate validates the ReportsSource spec. The activities can only be watched by a G Suite admin, and
+// cannot be polled, as they are carried by the push notifications.
+func (s *ReportsSourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	errs := s.GSuiteSourceSpec.Validate(ctx).Also(s.GSuiteSourceSpec.validatePushMode())
+	if s.EmailAddress == "" {
+		errs = errs.Also(apis.ErrMissingField("emailAddress"))
+	}
+	if len(s.Applications) == 0 {
+		errs = errs.Also(apis.ErrMissingField("applications"))
+	}
+	for i, application := range s.Applications {
+		if !applicationRegexp.MatchString(application) {
+			errs = errs.Also(apis.ErrInvalidArrayValue(application, "applications", i))
+		}
+	}
+	return errs
+}
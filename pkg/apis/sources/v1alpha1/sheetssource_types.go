@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/knative/pkg/apis/duck"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ GSuiteSource = (*SheetsSource)(nil)
+
+var _ = duck.VerifyType(&SheetsSource{}, &duckv1alpha1.Conditions{})
+
+type SheetsSourceSpec struct {
+	GSuiteSourceSpec `json:",inline"`
+
+	// SpreadsheetId is the ID of the spreadsheet to watch.
+	SpreadsheetId string `json:"spreadsheetId"`
+}
+
+const (
+	SheetsSourceEventType = "org.nachocano.source.gsuite.sheets"
+)
+
+const (
+	SheetsSourceConditionReady           = GSuiteSourceConditionReady
+	SheetsSourceConditionSecretsProvided = GSuiteSourceConditionSecretsProvided
+	SheetsSourceConditionSinkProvided    = GSuiteSourceConditionSinkProvided
+	SheetsSourceConditionServiceProvided = GSuiteSourceConditionServiceProvided
+	SheetsSourceConditionWebHookProvided = GSuiteSourceConditionWebHookProvided
+)
+
+type SheetsSourceStatus struct {
+	GSuiteSourceStatus `json:",inline"`
+}
+
+// GetGSuiteSpec implements GSuiteSource.
+func (s *SheetsSource) GetGSuiteSpec() *GSuiteSourceSpec {
+	return &s.Spec.GSuiteSourceSpec
+}
+
+// GetGSuiteStatus implements GSuiteSource.
+func (s *SheetsSource) GetGSuiteStatus() *GSuiteSourceStatus {
+	return &s.Status.GSuiteSourceStatus
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SheetsSource is the Schema for the sheetssources API.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:categories=all,knative,eventing,sources
+type SheetsSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SheetsSourceSpec   `json:"spec,omitempty"`
+	Status SheetsSourceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SheetsSourceList contains a list of SheetsSource.
+type SheetsSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SheetsSource `json:"items"`
+}
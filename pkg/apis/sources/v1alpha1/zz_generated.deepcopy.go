@@ -20,7 +20,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -88,11 +89,12 @@ func (in *CalendarSourceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CalendarSourceSpec) DeepCopyInto(out *CalendarSourceSpec) {
 	*out = *in
-	in.GcpCredsSecret.DeepCopyInto(&out.GcpCredsSecret)
-	if in.Sink != nil {
-		in, out := &in.Sink, &out.Sink
-		*out = new(v1.ObjectReference)
-		**out = **in
+	in.GSuiteSourceSpec.DeepCopyInto(&out.GSuiteSourceSpec)
+	in.GSuiteUsersSpec.DeepCopyInto(&out.GSuiteUsersSpec)
+	if in.CalendarIds != nil {
+		in, out := &in.CalendarIds, &out.CalendarIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
@@ -110,7 +112,7 @@ func (in *CalendarSourceSpec) DeepCopy() *CalendarSourceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CalendarSourceStatus) DeepCopyInto(out *CalendarSourceStatus) {
 	*out = *in
-	in.Status.DeepCopyInto(&out.Status)
+	in.GSuiteSourceStatus.DeepCopyInto(&out.GSuiteSourceStatus)
 	return
 }
 
@@ -124,6 +126,153 @@ func (in *CalendarSourceStatus) DeepCopy() *CalendarSourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEventsSpec) DeepCopyInto(out *CloudEventsSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEventsSpec.
+func (in *CloudEventsSpec) DeepCopy() *CloudEventsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEventsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackoffDelay != nil {
+		in, out := &in.BackoffDelay, &out.BackoffDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeliverySpec.
+func (in *DeliverySpec) DeepCopy() *DeliverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectorySource) DeepCopyInto(out *DirectorySource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectorySource.
+func (in *DirectorySource) DeepCopy() *DirectorySource {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectorySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DirectorySource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectorySourceList) DeepCopyInto(out *DirectorySourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DirectorySource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectorySourceList.
+func (in *DirectorySourceList) DeepCopy() *DirectorySourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectorySourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DirectorySourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectorySourceSpec) DeepCopyInto(out *DirectorySourceSpec) {
+	*out = *in
+	in.GSuiteSourceSpec.DeepCopyInto(&out.GSuiteSourceSpec)
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectorySourceSpec.
+func (in *DirectorySourceSpec) DeepCopy() *DirectorySourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectorySourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectorySourceStatus) DeepCopyInto(out *DirectorySourceStatus) {
+	*out = *in
+	in.GSuiteSourceStatus.DeepCopyInto(&out.GSuiteSourceStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectorySourceStatus.
+func (in *DirectorySourceStatus) DeepCopy() *DirectorySourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectorySourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriveSource) DeepCopyInto(out *DriveSource) {
 	*out = *in
@@ -188,12 +337,8 @@ func (in *DriveSourceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriveSourceSpec) DeepCopyInto(out *DriveSourceSpec) {
 	*out = *in
-	in.GcpCredsSecret.DeepCopyInto(&out.GcpCredsSecret)
-	if in.Sink != nil {
-		in, out := &in.Sink, &out.Sink
-		*out = new(v1.ObjectReference)
-		**out = **in
-	}
+	in.GSuiteSourceSpec.DeepCopyInto(&out.GSuiteSourceSpec)
+	in.GSuiteUsersSpec.DeepCopyInto(&out.GSuiteUsersSpec)
 	return
 }
 
@@ -210,7 +355,7 @@ func (in *DriveSourceSpec) DeepCopy() *DriveSourceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriveSourceStatus) DeepCopyInto(out *DriveSourceStatus) {
 	*out = *in
-	in.Status.DeepCopyInto(&out.Status)
+	in.GSuiteSourceStatus.DeepCopyInto(&out.GSuiteSourceStatus)
 	return
 }
 
@@ -223,3 +368,406 @@ func (in *DriveSourceStatus) DeepCopy() *DriveSourceStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSuiteSourceSpec) DeepCopyInto(out *GSuiteSourceSpec) {
+	*out = *in
+	in.GcpCredsSecret.DeepCopyInto(&out.GcpCredsSecret)
+	if in.Sink != nil {
+		in, out := &in.Sink, &out.Sink
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.DeadLetterSink != nil {
+		in, out := &in.DeadLetterSink, &out.DeadLetterSink
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.Delivery != nil {
+		in, out := &in.Delivery, &out.Delivery
+		*out = new(DeliverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CloudEvents != nil {
+		in, out := &in.CloudEvents, &out.CloudEvents
+		*out = new(CloudEventsSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSuiteSourceSpec.
+func (in *GSuiteSourceSpec) DeepCopy() *GSuiteSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GSuiteSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSuiteSourceStatus) DeepCopyInto(out *GSuiteSourceStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]Webhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSuiteSourceStatus.
+func (in *GSuiteSourceStatus) DeepCopy() *GSuiteSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GSuiteSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSuiteUsersSpec) DeepCopyInto(out *GSuiteUsersSpec) {
+	*out = *in
+	if in.EmailAddresses != nil {
+		in, out := &in.EmailAddresses, &out.EmailAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSuiteUsersSpec.
+func (in *GSuiteUsersSpec) DeepCopy() *GSuiteUsersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GSuiteUsersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GmailSource) DeepCopyInto(out *GmailSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GmailSource.
+func (in *GmailSource) DeepCopy() *GmailSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GmailSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GmailSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GmailSourceList) DeepCopyInto(out *GmailSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GmailSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GmailSourceList.
+func (in *GmailSourceList) DeepCopy() *GmailSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(GmailSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GmailSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GmailSourceSpec) DeepCopyInto(out *GmailSourceSpec) {
+	*out = *in
+	in.GSuiteSourceSpec.DeepCopyInto(&out.GSuiteSourceSpec)
+	in.GSuiteUsersSpec.DeepCopyInto(&out.GSuiteUsersSpec)
+	if in.LabelIds != nil {
+		in, out := &in.LabelIds, &out.LabelIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GmailSourceSpec.
+func (in *GmailSourceSpec) DeepCopy() *GmailSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GmailSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GmailSourceStatus) DeepCopyInto(out *GmailSourceStatus) {
+	*out = *in
+	in.GSuiteSourceStatus.DeepCopyInto(&out.GSuiteSourceStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GmailSourceStatus.
+func (in *GmailSourceStatus) DeepCopy() *GmailSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GmailSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportsSource) DeepCopyInto(out *ReportsSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportsSource.
+func (in *ReportsSource) DeepCopy() *ReportsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportsSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportsSourceList) DeepCopyInto(out *ReportsSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReportsSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportsSourceList.
+func (in *ReportsSourceList) DeepCopy() *ReportsSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportsSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportsSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportsSourceSpec) DeepCopyInto(out *ReportsSourceSpec) {
+	*out = *in
+	in.GSuiteSourceSpec.DeepCopyInto(&out.GSuiteSourceSpec)
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportsSourceSpec.
+func (in *ReportsSourceSpec) DeepCopy() *ReportsSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportsSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportsSourceStatus) DeepCopyInto(out *ReportsSourceStatus) {
+	*out = *in
+	in.GSuiteSourceStatus.DeepCopyInto(&out.GSuiteSourceStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportsSourceStatus.
+func (in *ReportsSourceStatus) DeepCopy() *ReportsSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportsSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SheetsSource) DeepCopyInto(out *SheetsSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SheetsSource.
+func (in *SheetsSource) DeepCopy() *SheetsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SheetsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SheetsSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SheetsSourceList) DeepCopyInto(out *SheetsSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SheetsSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SheetsSourceList.
+func (in *SheetsSourceList) DeepCopy() *SheetsSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(SheetsSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SheetsSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SheetsSourceSpec) DeepCopyInto(out *SheetsSourceSpec) {
+	*out = *in
+	in.GSuiteSourceSpec.DeepCopyInto(&out.GSuiteSourceSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SheetsSourceSpec.
+func (in *SheetsSourceSpec) DeepCopy() *SheetsSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SheetsSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SheetsSourceStatus) DeepCopyInto(out *SheetsSourceStatus) {
+	*out = *in
+	in.GSuiteSourceStatus.DeepCopyInto(&out.GSuiteSourceStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SheetsSourceStatus.
+func (in *SheetsSourceStatus) DeepCopy() *SheetsSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SheetsSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Webhook) DeepCopyInto(out *Webhook) {
+	*out = *in
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Webhook.
+func (in *Webhook) DeepCopy() *Webhook {
+	if in == nil {
+		return nil
+	}
+	out := new(Webhook)
+	in.DeepCopyInto(out)
+	return out
+}
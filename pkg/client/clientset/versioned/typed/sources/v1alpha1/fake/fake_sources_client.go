@@ -31,10 +31,26 @@ func (c *FakeSourcesV1alpha1) CalendarSources(namespace string) v1alpha1.Calenda
 	return &FakeCalendarSources{c, namespace}
 }
 
+func (c *FakeSourcesV1alpha1) DirectorySources(namespace string) v1alpha1.DirectorySourceInterface {
+	return &FakeDirectorySources{c, namespace}
+}
+
 func (c *FakeSourcesV1alpha1) DriveSources(namespace string) v1alpha1.DriveSourceInterface {
 	return &FakeDriveSources{c, namespace}
 }
 
+func (c *FakeSourcesV1alpha1) GmailSources(namespace string) v1alpha1.GmailSourceInterface {
+	return &FakeGmailSources{c, namespace}
+}
+
+func (c *FakeSourcesV1alpha1) ReportsSources(namespace string) v1alpha1.ReportsSourceInterface {
+	return &FakeReportsSources{c, namespace}
+}
+
+func (c *FakeSourcesV1alpha1) SheetsSources(namespace string) v1alpha1.SheetsSourceInterface {
+	return &FakeSheetsSources{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeSourcesV1alpha1) RESTClient() rest.Interface {
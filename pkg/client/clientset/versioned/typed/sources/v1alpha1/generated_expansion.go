@@ -19,4 +19,12 @@ package v1alpha1
 
 type CalendarSourceExpansion interface{}
 
+type DirectorySourceExpansion interface{}
+
 type DriveSourceExpansion interface{}
+
+type GmailSourceExpansion interface{}
+
+type ReportsSourceExpansion interface{}
+
+type SheetsSourceExpansion interface{}
@@ -27,7 +27,11 @@ import (
 type SourcesV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	CalendarSourcesGetter
+	DirectorySourcesGetter
 	DriveSourcesGetter
+	GmailSourcesGetter
+	ReportsSourcesGetter
+	SheetsSourcesGetter
 }
 
 // SourcesV1alpha1Client is used to interact with features provided by the sources.nachocano.org group.
@@ -39,10 +43,26 @@ func (c *SourcesV1alpha1Client) CalendarSources(namespace string) CalendarSource
 	return newCalendarSources(c, namespace)
 }
 
+func (c *SourcesV1alpha1Client) DirectorySources(namespace string) DirectorySourceInterface {
+	return newDirectorySources(c, namespace)
+}
+
 func (c *SourcesV1alpha1Client) DriveSources(namespace string) DriveSourceInterface {
 	return newDriveSources(c, namespace)
 }
 
+func (c *SourcesV1alpha1Client) GmailSources(namespace string) GmailSourceInterface {
+	return newGmailSources(c, namespace)
+}
+
+func (c *SourcesV1alpha1Client) ReportsSources(namespace string) ReportsSourceInterface {
+	return newReportsSources(c, namespace)
+}
+
+func (c *SourcesV1alpha1Client) SheetsSources(namespace string) SheetsSourceInterface {
+	return newSheetsSources(c, namespace)
+}
+
 // NewForConfig creates a new SourcesV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*SourcesV1alpha1Client, error) {
 	config := *c
@@ -25,8 +25,16 @@ import (
 type Interface interface {
 	// CalendarSources returns a CalendarSourceInformer.
 	CalendarSources() CalendarSourceInformer
+	// DirectorySources returns a DirectorySourceInformer.
+	DirectorySources() DirectorySourceInformer
 	// DriveSources returns a DriveSourceInformer.
 	DriveSources() DriveSourceInformer
+	// GmailSources returns a GmailSourceInformer.
+	GmailSources() GmailSourceInformer
+	// ReportsSources returns a ReportsSourceInformer.
+	ReportsSources() ReportsSourceInformer
+	// SheetsSources returns a SheetsSourceInformer.
+	SheetsSources() SheetsSourceInformer
 }
 
 type version struct {
@@ -45,7 +53,27 @@ func (v *version) CalendarSources() CalendarSourceInformer {
 	return &calendarSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// DirectorySources returns a DirectorySourceInformer.
+func (v *version) DirectorySources() DirectorySourceInformer {
+	return &directorySourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // DriveSources returns a DriveSourceInformer.
 func (v *version) DriveSources() DriveSourceInformer {
 	return &driveSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// GmailSources returns a GmailSourceInformer.
+func (v *version) GmailSources() GmailSourceInformer {
+	return &gmailSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ReportsSources returns a ReportsSourceInformer.
+func (v *version) ReportsSources() ReportsSourceInformer {
+	return &reportsSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SheetsSources returns a SheetsSourceInformer.
+func (v *version) SheetsSources() SheetsSourceInformer {
+	return &sheetsSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -25,6 +25,14 @@ type CalendarSourceListerExpansion interface{}
 // CalendarSourceNamespaceLister.
 type CalendarSourceNamespaceListerExpansion interface{}
 
+// DirectorySourceListerExpansion allows custom methods to be added to
+// DirectorySourceLister.
+type DirectorySourceListerExpansion interface{}
+
+// DirectorySourceNamespaceListerExpansion allows custom methods to be added to
+// DirectorySourceNamespaceLister.
+type DirectorySourceNamespaceListerExpansion interface{}
+
 // DriveSourceListerExpansion allows custom methods to be added to
 // DriveSourceLister.
 type DriveSourceListerExpansion interface{}
@@ -32,3 +40,27 @@ type DriveSourceListerExpansion interface{}
 // DriveSourceNamespaceListerExpansion allows custom methods to be added to
 // DriveSourceNamespaceLister.
 type DriveSourceNamespaceListerExpansion interface{}
+
+// GmailSourceListerExpansion allows custom methods to be added to
+// GmailSourceLister.
+type GmailSourceListerExpansion interface{}
+
+// GmailSourceNamespaceListerExpansion allows custom methods to be added to
+// GmailSourceNamespaceLister.
+type GmailSourceNamespaceListerExpansion interface{}
+
+// ReportsSourceListerExpansion allows custom methods to be added to
+// ReportsSourceLister.
+type ReportsSourceListerExpansion interface{}
+
+// ReportsSourceNamespaceListerExpansion allows custom methods to be added to
+// ReportsSourceNamespaceLister.
+type ReportsSourceNamespaceListerExpansion interface{}
+
+// SheetsSourceListerExpansion allows custom methods to be added to
+// SheetsSourceLister.
+type SheetsSourceListerExpansion interface{}
+
+// SheetsSourceNamespaceListerExpansion allows custom methods to be added to
+// SheetsSourceNamespaceLister.
+type SheetsSourceNamespaceListerExpansion interface{}
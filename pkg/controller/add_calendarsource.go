@@ -17,10 +17,15 @@ limitations under the License.
 package controller
 
 import (
-	"github.com/nachocano/gsuite-source/pkg/reconciler/calendar"
+	"github.com/nachocano/gsuite-source/pkg/gsuite/calendar"
+	"github.com/nachocano/gsuite-source/pkg/reconciler"
+	"go.uber.org/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, calendar.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, func(mgr manager.Manager, logger *zap.SugaredLogger) error {
+		return reconciler.Add(mgr, logger, calendar.Kind{})
+	})
 }
@@ -0,0 +1,300 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package calendar implements the G Suite kind for Google Calendar.
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	"github.com/knative/pkg/logging"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gscalendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	// primaryCalendarId is the ID of the primary calendar of the impersonated user.
+	primaryCalendarId = "primary"
+	// calendarEventsURL is the CloudEvents source of the events of a calendar.
+	calendarEventsURL = "https://www.googleapis.com/calendar/v3/calendars/%s/events"
+	// Events created and updated within this period are considered created.
+	createdThreshold = time.Second
+)
+
+// Kind is the Calendar G Suite kind.
+type Kind struct {
+	// ClientOptions are appended to the options of the Calendar API clients, e.g., to point them at a fake server in tests.
+	ClientOptions []option.ClientOption
+}
+
+var _ gsuite.PollingKind = Kind{}
+
+func (Kind) Name() string {
+	return "calendar"
+}
+
+func (Kind) NewSource() sourcesv1alpha1.GSuiteSource {
+	return &sourcesv1alpha1.CalendarSource{}
+}
+
+// ChannelExpiration is one week, Google might grant a shorter one.
+func (Kind) ChannelExpiration() time.Duration {
+	return 7 * 24 * time.Hour
+}
+
+func (Kind) ChannelRenewalPeriod() time.Duration {
+	return 24 * time.Hour
+}
+
+// SupportsPolling is true, as the Calendar events.list reads the changes from the sync token.
+func (Kind) SupportsPolling() bool {
+	return true
+}
+
+func (k Kind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	httpClient, err := gsuite.NewHTTPClient(ctx, credentials, email, gscalendar.CalendarReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]option.ClientOption{option.WithHTTPClient(httpClient)}, k.ClientOptions...)
+	svc, err := gscalendar.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		svc:   svc,
+		email: email,
+	}, nil
+}
+
+type client struct {
+	svc   *gscalendar.Service
+	email string
+}
+
+// Resources returns the IDs of the calendars of the user the source watches.
+func (c *client) Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	calendar, ok := source.(*sourcesv1alpha1.CalendarSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	if calendar.Spec.AllCalendars {
+		var ids []string
+		err := c.svc.CalendarList.List().Fields("nextPageToken,items(id,primary)").Pages(ctx, func(page *gscalendar.CalendarList) error {
+			for _, entry := range page.Items {
+				ids = append(ids, c.calendarId(entry.Id, entry.Primary))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list calendars: %v", err)
+		}
+		return ids, nil
+	}
+	if len(calendar.Spec.CalendarIds) > 0 {
+		var ids []string
+		for _, id := range calendar.Spec.CalendarIds {
+			ids = append(ids, c.calendarId(id, id == primaryCalendarId))
+		}
+		return ids, nil
+	}
+	return []string{c.calendarId(primaryCalendarId, true)}, nil
+}
+
+// calendarId returns the ID of a calendar, using the email address of the user for its primary calendar,
+// as it is its actual ID and "primary" does not identify the calendar across users.
+func (c *client) calendarId(id string, primary bool) string {
+	if primary && c.email != "" {
+		return c.email
+	}
+	return id
+}
+
+// Cursor performs a full synchronization of the calendar to get its first sync token.
+func (c *client) Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error) {
+	syncStart := time.Now()
+	_, syncToken, err := c.listEvents(ctx, resource, "", "nextPageToken,nextSyncToken")
+	if err != nil {
+		return "", err
+	}
+	return formatCursor(syncStart, syncToken), nil
+}
+
+// formatCursor returns the cursor of a synchronization, i.e., the time it started and the sync token it returned.
+// The time is part of the cursor, so that it is saved along with the token, and survives the restarts of
+// the receive adapter.
+func formatCursor(syncStart time.Time, syncToken string) string {
+	return syncStart.UTC().Format(time.RFC3339Nano) + " " + syncToken
+}
+
+// parseCursor returns the start time and the sync token of the synchronization of the cursor.
+// The cursors of the sources created before the time was part of them are just the sync token,
+// and their time is zero.
+func parseCursor(cursor string) (time.Time, string) {
+	parts := strings.SplitN(cursor, " ", 2)
+	if len(parts) != 2 {
+		return time.Time{}, cursor
+	}
+	syncStart, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, cursor
+	}
+	return syncStart, parts[1]
+}
+
+// Watch watches the events of the calendar.
+func (c *client) Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, channel *gsuite.Channel) (*gsuite.Channel, error) {
+	resp, err := c.svc.Events.Watch(resource, toCalendarChannel(channel)).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return fromCalendarChannel(resp), nil
+}
+
+func (c *client) Stop(ctx context.Context, channel *gsuite.Channel) error {
+	return c.svc.Channels.Stop(toCalendarChannel(channel)).Context(ctx).Do()
+}
+
+// Events incrementally synchronizes the events of the notified calendar from the sync token of the cursor, and returns
+// one event per calendar event. Calendar notifications do not have payloads, the actual events are read from the Events feed.
+func (c *client) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	calendarId := notification.Resource
+	lastSync, token := parseCursor(cursor)
+
+	syncStart := time.Now()
+	events, syncToken, err := c.listEvents(ctx, calendarId, token, "")
+	if isGone(err) {
+		// The sync token is no longer valid, we need to perform a full synchronization.
+		// As we cannot tell which events changed in the meantime, we only send the ones updated
+		// since the last successful synchronization, whose time is saved in the cursor.
+		logging.FromContext(ctx).Infof("Sync token %q invalidated, performing a full synchronization", token)
+		events, syncToken, err = c.listEvents(ctx, calendarId, "", "")
+		if err == nil {
+			events = updatedSince(events, lastSync)
+		}
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to synchronize events: %v", err)
+	}
+
+	source := fmt.Sprintf(calendarEventsURL, url.PathEscape(calendarId))
+	var ces []cloudevents.Event
+	for _, event := range events {
+		ces = append(ces, newEvent(event, source, notification))
+	}
+	return ces, formatCursor(syncStart, syncToken), nil
+}
+
+// listEvents lists all the events changed since syncToken, or all the events if syncToken is empty,
+// and returns them along with the token for the next synchronization.
+func (c *client) listEvents(ctx context.Context, calendarId, syncToken, fields string) ([]*gscalendar.Event, string, error) {
+	var events []*gscalendar.Event
+	var nextSyncToken string
+	call := c.svc.Events.List(calendarId)
+	if syncToken != "" {
+		call = call.SyncToken(syncToken)
+	}
+	if fields != "" {
+		call = call.Fields(googleapi.Field(fields))
+	}
+	err := call.Pages(ctx, func(page *gscalendar.Events) error {
+		events = append(events, page.Items...)
+		nextSyncToken = page.NextSyncToken
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return events, nextSyncToken, nil
+}
+
+func newEvent(event *gscalendar.Event, source string, notification *gsuite.Notification) cloudevents.Event {
+	id := fmt.Sprintf("%s-%s", event.Id, event.Updated)
+	return gsuite.NewEvent(id, eventType(event), source, event.Id, event.Updated, event, notification)
+}
+
+// eventType returns the CloudEvent type for the given calendar event.
+func eventType(event *gscalendar.Event) string {
+	if event.Status == "cancelled" {
+		return sourcesv1alpha1.CalendarSourceCancelledEventType
+	}
+	created, err := time.Parse(time.RFC3339, event.Created)
+	if err != nil {
+		return sourcesv1alpha1.CalendarSourceUpdatedEventType
+	}
+	updated, err := time.Parse(time.RFC3339, event.Updated)
+	if err != nil {
+		return sourcesv1alpha1.CalendarSourceUpdatedEventType
+	}
+	if updated.Sub(created) < createdThreshold {
+		return sourcesv1alpha1.CalendarSourceCreatedEventType
+	}
+	return sourcesv1alpha1.CalendarSourceUpdatedEventType
+}
+
+// updatedSince filters the events that were updated after t.
+// It returns no events if t is zero, i.e., for the cursors without time, as we do not know what was already sent.
+func updatedSince(events []*gscalendar.Event, t time.Time) []*gscalendar.Event {
+	var filtered []*gscalendar.Event
+	if t.IsZero() {
+		return filtered
+	}
+	for _, event := range events {
+		updated, err := time.Parse(time.RFC3339, event.Updated)
+		if err == nil && updated.After(t) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func isGone(err error) bool {
+	if e, ok := err.(*googleapi.Error); ok {
+		return e.Code == http.StatusGone
+	}
+	return false
+}
+
+func toCalendarChannel(channel *gsuite.Channel) *gscalendar.Channel {
+	return &gscalendar.Channel{
+		Id:         channel.Id,
+		ResourceId: channel.ResourceId,
+		Token:      channel.Token,
+		Address:    channel.Address,
+		Kind:       "api#channel",
+		Type:       "web_hook",
+		Expiration: gsuite.TimeToMillis(channel.Expiration),
+	}
+}
+
+func fromCalendarChannel(channel *gscalendar.Channel) *gsuite.Channel {
+	return &gsuite.Channel{
+		Id:         channel.Id,
+		ResourceId: channel.ResourceId,
+		Token:      channel.Token,
+		Address:    channel.Address,
+		Expiration: gsuite.MillisToTime(channel.Expiration),
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+	gscalendar "google.golang.org/api/calendar/v3"
+)
+
+const testUser = "user@example.com"
+
+func newTestClient(t *testing.T, api *gstesting.GoogleAPI) gsuite.Client {
+	t.Helper()
+	c, err := Kind{ClientOptions: api.CalendarOptions()}.NewClient(context.Background(), []byte(gstesting.Credentials), testUser)
+	if err != nil {
+		t.Fatalf("NewClient() = %v", err)
+	}
+	return c
+}
+
+func TestWatchPrimaryCalendar(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	ctx := context.Background()
+	source := &sourcesv1alpha1.CalendarSource{}
+
+	resources, err := c.Resources(ctx, source)
+	if err != nil {
+		t.Fatalf("Resources() = %v", err)
+	}
+	if len(resources) != 1 || resources[0] != testUser {
+		t.Fatalf("Resources() = %v, want [%s]", resources, testUser)
+	}
+	cursor, err := c.Cursor(ctx, source, resources[0])
+	if err != nil {
+		t.Fatalf("Cursor() = %v", err)
+	}
+	if syncStart, token := parseCursor(cursor); token != gstesting.SyncToken || syncStart.IsZero() {
+		t.Errorf("Cursor() = %q, want the time of the synchronization and %q", cursor, gstesting.SyncToken)
+	}
+
+	channel, err := c.Watch(ctx, source, resources[0], cursor, &gsuite.Channel{Id: "channel-1", Address: "https://adapter.example.com"})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	channels := api.Channels()
+	if len(channels) != 1 || channels[0].CalendarId != testUser || channels[0].ResourceId != channel.ResourceId {
+		t.Errorf("Channels() = %+v, want a channel watching %s", channels, testUser)
+	}
+}
+
+func TestEventsIncrementalSync(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	api.Script(gstesting.CalendarEventsList, gstesting.Response{Body: &gscalendar.Events{
+		NextSyncToken: "sync-token-2",
+		Items: []*gscalendar.Event{
+			{Id: "event-1", Created: "2019-05-01T10:00:00Z", Updated: "2019-05-01T10:00:00Z"},
+		},
+	}})
+
+	start := time.Now()
+	cursor := formatCursor(start.Add(-time.Hour), "sync-token-1")
+	events, cursor, err := c.Events(context.Background(), cursor, &gsuite.Notification{Resource: testUser, ResourceState: "exists"})
+	if err != nil {
+		t.Fatalf("Events() = %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Events() = %d events, want 1", len(events))
+	}
+	if syncStart, token := parseCursor(cursor); token != "sync-token-2" || syncStart.Before(start) {
+		t.Errorf("Events() cursor = %q, want %q synchronized after %v", cursor, "sync-token-2", start)
+	}
+	requests := api.Requests(gstesting.CalendarEventsList)
+	if len(requests) != 1 || requests[0].Query.Get("syncToken") != "sync-token-1" {
+		t.Errorf("events.list requests = %+v, want an incremental synchronization from %q", requests, "sync-token-1")
+	}
+}
+
+// TestEventsFullSyncWhenTokenGone synchronizes the events with a client that did not synchronize them before,
+// as after a restart of the receive adapter, and checks that only the events updated since the time in the
+// cursor are sent when the sync token is no longer valid.
+func TestEventsFullSyncWhenTokenGone(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	api.Script(gstesting.CalendarEventsList,
+		gstesting.ErrorResponse(http.StatusGone, "Sync token is no longer valid"),
+		gstesting.Response{Body: &gscalendar.Events{
+			NextSyncToken: "sync-token-2",
+			Items: []*gscalendar.Event{
+				{Id: "event-1", Created: "2019-05-01T10:00:00Z", Updated: "2019-05-01T10:00:00Z"},
+				{Id: "event-2", Created: "2019-05-01T10:00:00Z", Updated: "2019-05-01T13:00:00Z"},
+			},
+		}},
+	)
+
+	lastSync := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	events, cursor, err := c.Events(context.Background(), formatCursor(lastSync, "stale"), &gsuite.Notification{Resource: testUser, ResourceState: "exists"})
+	if err != nil {
+		t.Fatalf("Events() = %v", err)
+	}
+	if _, token := parseCursor(cursor); token != "sync-token-2" {
+		t.Errorf("Events() cursor = %q, want %q", cursor, "sync-token-2")
+	}
+	if len(events) != 1 || events[0].Context.AsV03().Subject == nil || *events[0].Context.AsV03().Subject != "event-2" {
+		t.Errorf("Events() = %v, want the one of event-2, updated since the last synchronization", events)
+	}
+	requests := api.Requests(gstesting.CalendarEventsList)
+	if len(requests) != 2 || requests[0].Query.Get("syncToken") != "stale" || requests[1].Query.Get("syncToken") != "" {
+		t.Errorf("events.list requests = %+v, want an incremental and then a full synchronization", requests)
+	}
+}
+
+// TestEventsFullSyncWithoutTime checks that no events are sent on a full synchronization from a cursor without
+// the time of its synchronization, as the ones of the sources created before the time was part of the cursors.
+func TestEventsFullSyncWithoutTime(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	api.Script(gstesting.CalendarEventsList,
+		gstesting.ErrorResponse(http.StatusGone, "Sync token is no longer valid"),
+		gstesting.Response{Body: &gscalendar.Events{
+			NextSyncToken: "sync-token-2",
+			Items: []*gscalendar.Event{
+				{Id: "event-1", Created: "2019-05-01T10:00:00Z", Updated: "2019-05-01T10:00:00Z"},
+			},
+		}},
+	)
+
+	events, cursor, err := c.Events(context.Background(), "stale", &gsuite.Notification{Resource: testUser, ResourceState: "exists"})
+	if err != nil {
+		t.Fatalf("Events() = %v", err)
+	}
+	if _, token := parseCursor(cursor); token != "sync-token-2" {
+		t.Errorf("Events() cursor = %q, want %q", cursor, "sync-token-2")
+	}
+	// We do not know which events changed since the last synchronization.
+	if len(events) != 0 {
+		t.Errorf("Events() = %d events, want none", len(events))
+	}
+	requests := api.Requests(gstesting.CalendarEventsList)
+	if len(requests) != 2 || requests[0].Query.Get("syncToken") != "stale" || requests[1].Query.Get("syncToken") != "" {
+		t.Errorf("events.list requests = %+v, want an incremental and then a full synchronization", requests)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gsuite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// DirectoryGroupMemberScope is the Directory API scope needed to list the members of a group.
+	DirectoryGroupMemberScope = "https://www.googleapis.com/auth/admin.directory.group.member.readonly"
+	// directoryMembersURL is the Directory API members.list endpoint.
+	directoryMembersURL = "https://www.googleapis.com/admin/directory/v1/groups/%s/members"
+)
+
+// Member is a member of a Google group.
+type Member struct {
+	Id    string `json:"id"`
+	Etag  string `json:"etag,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Role is the role of the member in the group, i.e., OWNER, MANAGER or MEMBER.
+	Role string `json:"role,omitempty"`
+	// Type is the type of the member, e.g., USER or GROUP.
+	Type   string `json:"type,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+type members struct {
+	Members       []Member `json:"members"`
+	NextPageToken string   `json:"nextPageToken"`
+}
+
+// GroupMembers returns the email addresses of the users of the given Google group, including the ones
+// of its nested groups. Listing the members requires impersonating a G Suite admin.
+func GroupMembers(ctx context.Context, credentials []byte, admin, group string) ([]string, error) {
+	client, err := NewHTTPClient(ctx, credentials, admin, DirectoryGroupMemberScope)
+	if err != nil {
+		return nil, err
+	}
+	members, err := ListMembers(ctx, client, group, true)
+	if err != nil {
+		return nil, err
+	}
+	var emails []string
+	for _, member := range members {
+		if member.Type == "USER" && member.Status != "SUSPENDED" {
+			emails = append(emails, member.Email)
+		}
+	}
+	return emails, nil
+}
+
+// ListMembers returns the members of the given Google group, along with the ones of its nested groups if derived is set.
+// The client must impersonate a G Suite admin.
+func ListMembers(ctx context.Context, client *http.Client, group string, derived bool) ([]Member, error) {
+	var all []Member
+	pageToken := ""
+	for {
+		params := url.Values{}
+		if derived {
+			params.Set("includeDerivedMembership", "true")
+		}
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		u := fmt.Sprintf(directoryMembersURL, url.PathEscape(group)) + "?" + params.Encode()
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page members
+		if err := DoJSON(client, req.WithContext(ctx), &page); err != nil {
+			return nil, fmt.Errorf("failed to list members of group %q: %v", group, err)
+		}
+		all = append(all, page.Members...)
+		if page.NextPageToken == "" {
+			return all, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package directory implements the G Suite kind for the Admin SDK Directory users and groups.
+//
+// The users are watched with push notification channels, one per user event. The Directory API cannot
+// watch the members of the groups, so the receive adapter polls them instead, and sends an event for
+// every member added or removed since the previous poll.
+package directory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+)
+
+const (
+	// directoryUserScope is the Directory API scope needed to watch the users.
+	directoryUserScope = "https://www.googleapis.com/auth/admin.directory.user.readonly"
+	// watchURL is the Directory API users.watch endpoint.
+	watchURL = "https://www.googleapis.com/admin/directory/v1/users/watch"
+	// stopURL is the Directory API channels.stop endpoint.
+	stopURL = "https://www.googleapis.com/admin/directory_v1/channels/stop"
+	// groupURL is the source of the CloudEvents of the membership changes of a group.
+	groupURL = "https://www.googleapis.com/admin/directory/v1/groups/%s/members"
+
+	// usersPrefix and groupsPrefix prefix the user event and group resources, e.g., users/add or groups/<email>.
+	usersPrefix  = "users/"
+	groupsPrefix = "groups/"
+
+	// customer is the alias of the account of the impersonated admin.
+	customer = "my_customer"
+
+	// membersPollInterval is how often the members of the groups are polled.
+	membersPollInterval = time.Minute
+	// membersCursorVersion starts the members cursors, so that the ones of older formats are told apart.
+	membersCursorVersion = "v2"
+)
+
+// MemberChange is the data of the CloudEvent sent for each member added to or removed from a group.
+type MemberChange struct {
+	Group string `json:"group"`
+	gsuite.Member
+}
+
+// Kind is the Directory G Suite kind.
+type Kind struct{}
+
+var _ gsuite.WatchParamsKind = Kind{}
+
+func (Kind) Name() string {
+	return "directory"
+}
+
+func (Kind) NewSource() sourcesv1alpha1.GSuiteSource {
+	return &sourcesv1alpha1.DirectorySource{}
+}
+
+// ChannelExpiration is six hours, the maximum allowed by the Directory API.
+func (Kind) ChannelExpiration() time.Duration {
+	return 6 * time.Hour
+}
+
+func (Kind) ChannelRenewalPeriod() time.Duration {
+	return time.Hour
+}
+
+// WatchParams is the domain whose users are watched, if any.
+func (Kind) WatchParams(source sourcesv1alpha1.GSuiteSource) string {
+	if directory, ok := source.(*sourcesv1alpha1.DirectorySource); ok {
+		return directory.Spec.Domain
+	}
+	return ""
+}
+
+// NewClient returns a Directory client impersonating the given G Suite admin.
+func (Kind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	httpClient, err := gsuite.NewHTTPClient(ctx, credentials, email, directoryUserScope, gsuite.DirectoryGroupMemberScope)
+	if err != nil {
+		return nil, err
+	}
+	return &client{http: httpClient}, nil
+}
+
+type client struct {
+	http *http.Client
+}
+
+var _ gsuite.Poller = (*client)(nil)
+
+type channel struct {
+	Id         string `json:"id"`
+	ResourceId string `json:"resourceId,omitempty"`
+	Token      string `json:"token,omitempty"`
+	Address    string `json:"address,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Expiration string `json:"expiration,omitempty"`
+}
+
+// user are the fields of the user in the push notifications we use to identify the event.
+type user struct {
+	Id           string `json:"id"`
+	Etag         string `json:"etag"`
+	PrimaryEmail string `json:"primaryEmail"`
+}
+
+// Resources returns a resource per user event, and a resource per group.
+func (c *client) Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	directory, ok := source.(*sourcesv1alpha1.DirectorySource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	events := directory.Spec.Events
+	if len(events) == 0 {
+		events = sourcesv1alpha1.DirectorySourceUserEvents
+	}
+	var resources []string
+	for _, event := range events {
+		resources = append(resources, usersPrefix+event)
+	}
+	for _, group := range directory.Spec.Groups {
+		resources = append(resources, groupsPrefix+strings.ToLower(group))
+	}
+	return resources, nil
+}
+
+// Cursor returns an empty cursor. The receive adapter takes a snapshot of the members of the groups on its first poll.
+func (c *client) Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error) {
+	return "", nil
+}
+
+// Watch watches the user event resource. The group resources are polled, so no channel is created for them.
+func (c *client) Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, ch *gsuite.Channel) (*gsuite.Channel, error) {
+	directory, ok := source.(*sourcesv1alpha1.DirectorySource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	if strings.HasPrefix(resource, groupsPrefix) {
+		return &gsuite.Channel{Id: ch.Id, ResourceId: resource}, nil
+	}
+
+	params := url.Values{}
+	params.Set("event", strings.TrimPrefix(resource, usersPrefix))
+	if directory.Spec.Domain != "" {
+		params.Set("domain", directory.Spec.Domain)
+	} else {
+		params.Set("customer", customer)
+	}
+	req := &channel{
+		Id:         ch.Id,
+		Token:      ch.Token,
+		Address:    ch.Address,
+		Type:       "web_hook",
+		Expiration: strconv.FormatInt(gsuite.TimeToMillis(ch.Expiration), 10),
+	}
+	var resp channel
+	if err := c.do(ctx, watchURL+"?"+params.Encode(), req, &resp); err != nil {
+		return nil, err
+	}
+	watched := &gsuite.Channel{
+		Id:         resp.Id,
+		ResourceId: resp.ResourceId,
+		Token:      ch.Token,
+		Address:    ch.Address,
+	}
+	if resp.Expiration != "" {
+		expiration, err := strconv.ParseInt(resp.Expiration, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel expiration %q: %v", resp.Expiration, err)
+		}
+		watched.Expiration = gsuite.MillisToTime(expiration)
+	}
+	return watched, nil
+}
+
+// Stop stops the channel of a user event resource.
+func (c *client) Stop(ctx context.Context, ch *gsuite.Channel) error {
+	if strings.HasPrefix(ch.ResourceId, groupsPrefix) {
+		return nil
+	}
+	return c.do(ctx, stopURL, &channel{Id: ch.Id, ResourceId: ch.ResourceId}, nil)
+}
+
+// PollInterval implements gsuite.Poller. The group resources are polled, and the user event ones are watched.
+func (c *client) PollInterval(resource string) time.Duration {
+	if strings.HasPrefix(resource, groupsPrefix) {
+		return membersPollInterval
+	}
+	return 0
+}
+
+// Events returns the event of the user in the push notification, with type org.nachocano.source.gsuite.directory.user.<event>,
+// or the membership changes of the polled group.
+func (c *client) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	if strings.HasPrefix(notification.Resource, groupsPrefix) {
+		return c.memberEvents(ctx, cursor, notification)
+	}
+
+	var u user
+	if err := json.Unmarshal(notification.Data, &u); err != nil {
+		return nil, "", fmt.Errorf("invalid user: %v", err)
+	}
+	event := strings.TrimPrefix(notification.Resource, usersPrefix)
+	id := fmt.Sprintf("%s-%s-%s", u.Id, event, strings.Trim(u.Etag, `"`))
+	eventType := fmt.Sprintf("%s.%s", sourcesv1alpha1.DirectorySourceUserEventType, event)
+	// The data is the user resource of the notification, as is.
+	data := json.RawMessage(notification.Data)
+	return []cloudevents.Event{gsuite.NewEvent(id, eventType, notification.ResourceURI, u.PrimaryEmail, "", data, notification)}, cursor, nil
+}
+
+// memberEvents lists the members of the group, and returns an event for every member added or removed since the
+// members in the cursor, along with the current members as the new cursor. There are no events on the first poll.
+func (c *client) memberEvents(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	group := strings.TrimPrefix(notification.Resource, groupsPrefix)
+	members, err := gsuite.ListMembers(ctx, c.http, group, false)
+	if err != nil {
+		return nil, "", err
+	}
+	events, newCursor := memberChangeEvents(group, cursor, members, notification)
+	return events, newCursor, nil
+}
+
+// memberChangeEvents returns an event for every member added to or removed from the group since the members in
+// the cursor, along with the cursor of the current members.
+//
+// The event IDs are made of the group, the member ID and the member etag, so that the events of a poll
+// retried with the same cursor have the same IDs.
+func memberChangeEvents(group, cursor string, members []gsuite.Member, notification *gsuite.Notification) ([]cloudevents.Event, string) {
+	current := make(map[string]gsuite.Member, len(members))
+	for _, member := range members {
+		member.Etag = strings.Trim(member.Etag, `"`)
+		current[member.Id] = member
+	}
+	newCursor := membersCursor(current)
+	previous, ok := parseMembersCursor(cursor)
+	if !ok {
+		// First poll, or a cursor from an older version.
+		return nil, newCursor
+	}
+
+	source := fmt.Sprintf(groupURL, url.PathEscape(group))
+	var events []cloudevents.Event
+	newEvent := func(member gsuite.Member, eventType, change string) {
+		id := fmt.Sprintf("%s-%s-%s-%s", group, member.Id, member.Etag, change)
+		subject := member.Email
+		if subject == "" {
+			subject = member.Id
+		}
+		events = append(events, gsuite.NewEvent(id, eventType, source, subject, "", &MemberChange{Group: group, Member: member}, notification))
+	}
+	for _, id := range sortedKeys(current) {
+		if _, ok := previous[id]; !ok {
+			newEvent(current[id], sourcesv1alpha1.DirectorySourceMemberAddedEventType, "added")
+		}
+	}
+	// Only the ID and etag of the removed members are known.
+	for _, id := range sortedKeys(previous) {
+		if _, ok := current[id]; !ok {
+			newEvent(previous[id], sourcesv1alpha1.DirectorySourceMemberRemovedEventType, "removed")
+		}
+	}
+	return events, newCursor
+}
+
+// membersCursor returns the cursor with the given members, by ID. The cursor is kept in the cursors ConfigMap
+// of the source, so it only has the version of its format followed by the sorted ID:etag pairs of the members.
+func membersCursor(members map[string]gsuite.Member) string {
+	entries := []string{membersCursorVersion}
+	for _, id := range sortedKeys(members) {
+		entries = append(entries, id+":"+members[id].Etag)
+	}
+	return strings.Join(entries, " ")
+}
+
+// parseMembersCursor returns the members in the cursor, with just their ID and etag, by ID. It returns false
+// if the cursor has no members snapshot.
+func parseMembersCursor(cursor string) (map[string]gsuite.Member, bool) {
+	entries := strings.Fields(cursor)
+	if len(entries) == 0 || entries[0] != membersCursorVersion {
+		return nil, false
+	}
+	members := make(map[string]gsuite.Member, len(entries)-1)
+	for _, entry := range entries[1:] {
+		i := strings.Index(entry, ":")
+		if i <= 0 {
+			return nil, false
+		}
+		members[entry[:i]] = gsuite.Member{Id: entry[:i], Etag: entry[i+1:]}
+	}
+	return members, true
+}
+
+func sortedKeys(members map[string]gsuite.Member) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *client) do(ctx context.Context, u string, body, v interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return gsuite.DoJSON(c.http, req.WithContext(ctx), v)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directory
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+)
+
+const testGroup = "eng@example.com"
+
+func TestMemberChangeEvents(t *testing.T) {
+	notification := &gsuite.Notification{Resource: groupsPrefix + testGroup, User: "admin@example.com"}
+	alice := gsuite.Member{Id: "1", Etag: `"etag/1"`, Email: "alice@example.com", Role: "MEMBER", Type: "USER"}
+	bob := gsuite.Member{Id: "2", Etag: `"etag/2"`, Email: "bob@example.com", Role: "MEMBER", Type: "USER"}
+	carol := gsuite.Member{Id: "3", Etag: `"etag/3"`, Email: "carol@example.com", Role: "OWNER", Type: "USER"}
+
+	// The first poll takes a snapshot of the members.
+	events, cursor := memberChangeEvents(testGroup, "", []gsuite.Member{bob, alice}, notification)
+	if len(events) != 0 {
+		t.Errorf("memberChangeEvents() returned %d events on the first poll, want none", len(events))
+	}
+	if want := "v2 1:etag/1 2:etag/2"; cursor != want {
+		t.Errorf("memberChangeEvents() cursor = %q, want %q", cursor, want)
+	}
+
+	// Bob is removed and Carol is added.
+	events, newCursor := memberChangeEvents(testGroup, cursor, []gsuite.Member{alice, carol}, notification)
+	if want := "v2 1:etag/1 3:etag/3"; newCursor != want {
+		t.Errorf("memberChangeEvents() cursor = %q, want %q", newCursor, want)
+	}
+	type event struct {
+		ID, Type, Subject string
+		Data              MemberChange
+	}
+	var got []event
+	for _, e := range events {
+		data, ok := e.Data.(*MemberChange)
+		if !ok {
+			t.Fatalf("event data = %T, want *MemberChange", e.Data)
+		}
+		got = append(got, event{ID: e.ID(), Type: e.Type(), Subject: *e.Context.AsV03().Subject, Data: *data})
+	}
+	carol.Etag = "etag/3"
+	want := []event{{
+		ID:      testGroup + "-3-etag/3-added",
+		Type:    sourcesv1alpha1.DirectorySourceMemberAddedEventType,
+		Subject: carol.Email,
+		Data:    MemberChange{Group: testGroup, Member: carol},
+	}, {
+		ID:      testGroup + "-2-etag/2-removed",
+		Type:    sourcesv1alpha1.DirectorySourceMemberRemovedEventType,
+		Subject: "2",
+		Data:    MemberChange{Group: testGroup, Member: gsuite.Member{Id: "2", Etag: "etag/2"}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("memberChangeEvents() (-want, +got) = %v", diff)
+	}
+
+	// Polling again with the same cursor returns the same event IDs.
+	again, _ := memberChangeEvents(testGroup, cursor, []gsuite.Member{alice, carol}, notification)
+	for i := range again {
+		if again[i].ID() != events[i].ID() {
+			t.Errorf("event %d ID = %q, want %q", i, again[i].ID(), events[i].ID())
+		}
+	}
+}
+
+func TestMemberChangeEventsCursors(t *testing.T) {
+	members := []gsuite.Member{{Id: "1", Etag: "etag/1", Email: "alice@example.com"}}
+	tests := map[string]struct {
+		cursor     string
+		wantEvents int
+	}{
+		"no snapshot":     {cursor: ""},
+		"older cursor":    {cursor: `{"bob@example.com":{"email":"bob@example.com"}}`},
+		"invalid cursor":  {cursor: "v2 1"},
+		"empty group":     {cursor: "v2", wantEvents: 1},
+		"unchanged group": {cursor: "v2 1:etag/1"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			events, cursor := memberChangeEvents(testGroup, tc.cursor, members, &gsuite.Notification{})
+			if len(events) != tc.wantEvents {
+				t.Errorf("memberChangeEvents() returned %d events, want %d", len(events), tc.wantEvents)
+			}
+			if want := "v2 1:etag/1"; cursor != want {
+				t.Errorf("memberChangeEvents() cursor = %q, want %q", cursor, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drive implements the G Suite kind for Google Drive.
+package drive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gsdrive "google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	// changesFields are the fields we request from the Drive Changes feed.
+	changesFields = "nextPageToken,newStartPageToken,changes(fileId,removed,time,teamDriveId,file(name,mimeType,modifiedTime,lastModifyingUser(displayName,emailAddress)))"
+
+	// allDrivesResource is the resource to watch the drive of the user along with all the shared drives it can access.
+	// The other resources are the empty one, to watch just the drive of the user, and the IDs of shared drives.
+	allDrivesResource = "*"
+
+	// changesURL is the source of the CloudEvents of the polled changes, which have no resource URI.
+	changesURL = "https://www.googleapis.com/drive/v3/changes"
+)
+
+// Change is the data of the CloudEvent sent for each Drive change.
+type Change struct {
+	FileId       string `json:"fileId"`
+	DriveId      string `json:"driveId,omitempty"`
+	Name         string `json:"name,omitempty"`
+	MimeType     string `json:"mimeType,omitempty"`
+	ModifiedTime string `json:"modifiedTime,omitempty"`
+	Removed      bool   `json:"removed"`
+	Actor        *Actor `json:"actor,omitempty"`
+}
+
+// Actor is the user who last modified the file.
+type Actor struct {
+	DisplayName  string `json:"displayName,omitempty"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+}
+
+// Kind is the Drive G Suite kind.
+type Kind struct {
+	// ClientOptions are appended to the options of the Drive API clients, e.g., to point them at a fake server in tests.
+	ClientOptions []option.ClientOption
+}
+
+var _ gsuite.PollingKind = Kind{}
+
+func (Kind) Name() string {
+	return "drive"
+}
+
+func (Kind) NewSource() sourcesv1alpha1.GSuiteSource {
+	return &sourcesv1alpha1.DriveSource{}
+}
+
+// ChannelExpiration is one week, Google might grant a shorter one.
+func (Kind) ChannelExpiration() time.Duration {
+	return 7 * 24 * time.Hour
+}
+
+func (Kind) ChannelRenewalPeriod() time.Duration {
+	return 24 * time.Hour
+}
+
+// SupportsPolling is true, as the Drive changes.list reads the changes from the page token.
+func (Kind) SupportsPolling() bool {
+	return true
+}
+
+func (k Kind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	httpClient, err := gsuite.NewHTTPClient(ctx, credentials, email, gsdrive.DriveReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]option.ClientOption{option.WithHTTPClient(httpClient)}, k.ClientOptions...)
+	svc, err := gsdrive.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &client{svc: svc}, nil
+}
+
+type client struct {
+	svc *gsdrive.Service
+}
+
+// Resources returns a single resource, either a shared drive, the drive of the user,
+// or the drive of the user along with its shared drives.
+func (c *client) Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	drive, ok := source.(*sourcesv1alpha1.DriveSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	if drive.Spec.DriveId != "" {
+		return []string{drive.Spec.DriveId}, nil
+	}
+	if drive.Spec.AllDrives {
+		return []string{allDrivesResource}, nil
+	}
+	return []string{""}, nil
+}
+
+// Cursor returns the Drive Changes start page token.
+func (c *client) Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error) {
+	resp, err := c.svc.Changes.GetStartPageToken().Context(ctx).Do(startPageTokenOptions(resource)...)
+	if err != nil {
+		return "", err
+	}
+	return resp.StartPageToken, nil
+}
+
+// Watch watches the Changes feed from the page token cursor.
+func (c *client) Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, channel *gsuite.Channel) (*gsuite.Channel, error) {
+	resp, err := c.svc.Changes.Watch(cursor, ToChannel(channel)).Context(ctx).Do(changesOptions(resource)...)
+	if err != nil {
+		return nil, err
+	}
+	return FromChannel(resp), nil
+}
+
+func (c *client) Stop(ctx context.Context, channel *gsuite.Channel) error {
+	return c.svc.Channels.Stop(ToChannel(channel)).Context(ctx).Do()
+}
+
+// Events lists the changes from the page token cursor, and returns one event per change.
+// The push notification body is empty for Drive, so we read the actual changes from the Changes feed.
+func (c *client) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	var events []cloudevents.Event
+	pageToken := cursor
+	for pageToken != "" {
+		changeList, err := c.svc.Changes.List(pageToken).Fields(changesFields).Context(ctx).Do(changesOptions(notification.Resource)...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list changes from page token %q: %v", pageToken, err)
+		}
+		for _, change := range changeList.Changes {
+			// Skip the changes to the shared drives themselves.
+			if change.FileId == "" {
+				continue
+			}
+			events = append(events, newEvent(change, notification))
+		}
+		if changeList.NewStartPageToken != "" {
+			return events, changeList.NewStartPageToken, nil
+		}
+		pageToken = changeList.NextPageToken
+	}
+	return events, cursor, nil
+}
+
+func newEvent(change *gsdrive.Change, notification *gsuite.Notification) cloudevents.Event {
+	data := &Change{
+		FileId:  change.FileId,
+		DriveId: change.TeamDriveId,
+		Removed: change.Removed,
+	}
+	if data.DriveId == "" && notification.Resource != allDrivesResource {
+		data.DriveId = notification.Resource
+	}
+	if change.File != nil {
+		data.Name = change.File.Name
+		data.MimeType = change.File.MimeType
+		data.ModifiedTime = change.File.ModifiedTime
+		if user := change.File.LastModifyingUser; user != nil {
+			data.Actor = &Actor{
+				DisplayName:  user.DisplayName,
+				EmailAddress: user.EmailAddress,
+			}
+		}
+	}
+	source := notification.ResourceURI
+	if source == "" {
+		source = changesURL
+	}
+	id := fmt.Sprintf("%s-%s", change.FileId, change.Time)
+	return gsuite.NewEvent(id, sourcesv1alpha1.DriveSourceEventType, source, change.FileId, change.Time, data, notification)
+}
+
+// param is a query parameter of a Drive API call. We use them for the shared drives parameters,
+// as they are not supported yet by the Drive API client.
+type param [2]string
+
+func (p param) Get() (string, string) {
+	return p[0], p[1]
+}
+
+// startPageTokenOptions returns the parameters of the Changes.GetStartPageToken calls for the given resource.
+func startPageTokenOptions(resource string) []googleapi.CallOption {
+	opts := []googleapi.CallOption{SupportsAllDrives}
+	if resource != "" && resource != allDrivesResource {
+		opts = append(opts, param{"driveId", resource})
+	}
+	return opts
+}
+
+// changesOptions returns the parameters of the Changes.Watch and Changes.List calls for the given resource.
+func changesOptions(resource string) []googleapi.CallOption {
+	opts := startPageTokenOptions(resource)
+	if resource != "" {
+		opts = append(opts, param{"includeItemsFromAllDrives", "true"})
+	}
+	return opts
+}
+
+// SupportsAllDrives is the option of the Drive API calls on files that may be in shared drives.
+var SupportsAllDrives googleapi.CallOption = param{"supportsAllDrives", "true"}
+
+// ToChannel returns the Drive API channel of the given channel, e.g., to watch or stop it.
+func ToChannel(channel *gsuite.Channel) *gsdrive.Channel {
+	return &gsdrive.Channel{
+		Id:         channel.Id,
+		ResourceId: channel.ResourceId,
+		Token:      channel.Token,
+		Address:    channel.Address,
+		Kind:       "api#channel",
+		Type:       "web_hook",
+		Expiration: gsuite.TimeToMillis(channel.Expiration),
+	}
+}
+
+// FromChannel returns the channel of the given Drive API channel.
+func FromChannel(channel *gsdrive.Channel) *gsuite.Channel {
+	return &gsuite.Channel{
+		Id:         channel.Id,
+		ResourceId: channel.ResourceId,
+		Token:      channel.Token,
+		Address:    channel.Address,
+		Expiration: gsuite.MillisToTime(channel.Expiration),
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drive
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+	gsdrive "google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+func newTestClient(t *testing.T, api *gstesting.GoogleAPI) gsuite.Client {
+	t.Helper()
+	c, err := Kind{ClientOptions: api.DriveOptions()}.NewClient(context.Background(), []byte(gstesting.Credentials), "user@example.com")
+	if err != nil {
+		t.Fatalf("NewClient() = %v", err)
+	}
+	return c
+}
+
+func TestWatchAndStop(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	ctx := context.Background()
+	source := &sourcesv1alpha1.DriveSource{}
+
+	cursor, err := c.Cursor(ctx, source, "")
+	if err != nil {
+		t.Fatalf("Cursor() = %v", err)
+	}
+	if cursor != gstesting.StartPageToken {
+		t.Errorf("Cursor() = %q, want %q", cursor, gstesting.StartPageToken)
+	}
+
+	expiration := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	channel, err := c.Watch(ctx, source, "", cursor, &gsuite.Channel{
+		Id:         "channel-1",
+		Token:      "token",
+		Address:    "https://adapter.example.com/user%40example.com/",
+		Expiration: expiration,
+	})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	if channel.Id != "channel-1" || channel.ResourceId == "" || !channel.Expiration.Equal(expiration) {
+		t.Errorf("Watch() = %+v, want channel-1 with a resource ID expiring at %v", channel, expiration)
+	}
+	if got := api.Requests(gstesting.DriveChangesWatch)[0].Query.Get("pageToken"); got != cursor {
+		t.Errorf("changes.watch pageToken = %q, want %q", got, cursor)
+	}
+	if got := len(api.Channels()); got != 1 {
+		t.Fatalf("got %d channels, want 1", got)
+	}
+
+	if err := c.Stop(ctx, channel); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if got := len(api.Channels()); got != 0 {
+		t.Errorf("got %d channels after Stop(), want 0", got)
+	}
+}
+
+func TestWatchError(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	api.Script(gstesting.DriveChangesWatch, gstesting.ErrorResponse(http.StatusForbidden, "forbidden"))
+
+	_, err := c.Watch(context.Background(), &sourcesv1alpha1.DriveSource{}, "", "1", &gsuite.Channel{Id: "channel-1", Address: "https://adapter.example.com"})
+	gerr, ok := err.(*googleapi.Error)
+	if !ok || gerr.Code != http.StatusForbidden {
+		t.Errorf("Watch() = %v, want a 403 Google API error", err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	api.Script(gstesting.DriveChangesList,
+		gstesting.Response{Body: &gsdrive.ChangeList{
+			NextPageToken: "2",
+			Changes: []*gsdrive.Change{
+				{FileId: "file-1", Time: "2019-05-01T10:00:00Z", File: &gsdrive.File{Name: "a.txt"}},
+				// A change to a shared drive itself, which is skipped.
+				{TeamDriveId: "drive-1", Time: "2019-05-01T10:00:01Z"},
+			},
+		}},
+		gstesting.Response{Body: &gsdrive.ChangeList{
+			NewStartPageToken: "3",
+			Changes: []*gsdrive.Change{
+				{FileId: "file-2", Time: "2019-05-01T10:00:02Z", Removed: true},
+			},
+		}},
+	)
+
+	events, cursor, err := c.Events(context.Background(), "1", &gsuite.Notification{ResourceState: "change"})
+	if err != nil {
+		t.Fatalf("Events() = %v", err)
+	}
+	if cursor != "3" {
+		t.Errorf("Events() cursor = %q, want %q", cursor, "3")
+	}
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID())
+	}
+	want := []string{"file-1-2019-05-01T10:00:00Z", "file-2-2019-05-01T10:00:02Z"}
+	if len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] {
+		t.Errorf("Events() IDs = %v, want %v", ids, want)
+	}
+	if got := api.Requests(gstesting.DriveChangesList)[1].Query.Get("pageToken"); got != "2" {
+		t.Errorf("second changes.list pageToken = %q, want %q", got, "2")
+	}
+}
@@ -0,0 +1,409 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gmail implements the G Suite kind for Gmail.
+//
+// Gmail does not support push notification channels. Instead, it publishes the notifications of the
+// watched mailboxes to a Pub/Sub topic. The G Suite client creates a push subscription to that topic
+// for every user, whose endpoint is the address the other kinds use for their channels, and the receive
+// adapter accepts the Pub/Sub push envelopes sent to it.
+package gmail
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// gmailReadonlyScope is the Gmail API scope needed to watch the mailboxes and read their history.
+	gmailReadonlyScope = "https://www.googleapis.com/auth/gmail.readonly"
+	gmailURL           = "https://www.googleapis.com/gmail/v1/users/me/"
+	// historyURL is the source of the CloudEvents, the history of the mailbox of a user.
+	historyURL = "https://www.googleapis.com/gmail/v1/users/%s/history"
+
+	// labelsSeparator separates the label IDs in the resources.
+	labelsSeparator = ","
+)
+
+// Change is the data of the CloudEvent sent for each mailbox change.
+type Change struct {
+	MessageId string `json:"messageId"`
+	ThreadId  string `json:"threadId,omitempty"`
+	// LabelIds are the labels of the message.
+	LabelIds []string `json:"labelIds,omitempty"`
+	// ChangedLabelIds are the labels added to or removed from the message, for the label events.
+	ChangedLabelIds []string `json:"changedLabelIds,omitempty"`
+	HistoryId       string   `json:"historyId"`
+}
+
+// Kind is the Gmail G Suite kind.
+type Kind struct {
+	// Endpoint and PubsubEndpoint override the base URLs of the Gmail and Pub/Sub APIs, and HTTPClient
+	// the authenticated clients of both, e.g., to point them at a fake server in tests.
+	Endpoint       string
+	PubsubEndpoint string
+	HTTPClient     *http.Client
+}
+
+var (
+	_ gsuite.PollingKind     = Kind{}
+	_ gsuite.WatchParamsKind = Kind{}
+
+	_ gsuite.ReplacedStopper = &client{}
+)
+
+func (Kind) Name() string {
+	return "gmail"
+}
+
+func (Kind) NewSource() sourcesv1alpha1.GSuiteSource {
+	return &sourcesv1alpha1.GmailSource{}
+}
+
+// ChannelExpiration is seven days, the expiration of the Gmail watches.
+func (Kind) ChannelExpiration() time.Duration {
+	return 7 * 24 * time.Hour
+}
+
+// ChannelRenewalPeriod renews the Gmail watches every day, as recommended by the Gmail API.
+func (Kind) ChannelRenewalPeriod() time.Duration {
+	return 6 * 24 * time.Hour
+}
+
+// SupportsPolling is true, as the Gmail history.list reads the changes from the history ID.
+func (Kind) SupportsPolling() bool {
+	return true
+}
+
+// WatchParams is the Pub/Sub topic the watches publish to.
+func (Kind) WatchParams(source sourcesv1alpha1.GSuiteSource) string {
+	if gmail, ok := source.(*sourcesv1alpha1.GmailSource); ok {
+		return gmail.Spec.Topic
+	}
+	return ""
+}
+
+// NewClient returns a Gmail client impersonating the given user.
+func (k Kind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	if email == "" {
+		return nil, fmt.Errorf("gmail requires a user to impersonate")
+	}
+	c := &client{gmail: k.HTTPClient, baseURL: k.Endpoint, email: email}
+	if c.gmail == nil {
+		var err error
+		if c.gmail, err = gsuite.NewHTTPClient(ctx, credentials, email, gmailReadonlyScope); err != nil {
+			return nil, err
+		}
+	}
+	if c.baseURL == "" {
+		c.baseURL = gmailURL
+	}
+	if k.PubsubEndpoint != "" {
+		c.pubsub = &pubsub{client: k.HTTPClient, baseURL: k.PubsubEndpoint}
+		if c.pubsub.client == nil {
+			c.pubsub.client = http.DefaultClient
+		}
+		return c, nil
+	}
+	var err error
+	if c.pubsub, err = newPubsub(ctx, credentials); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type client struct {
+	gmail   *http.Client
+	baseURL string
+	pubsub  *pubsub
+	email   string
+}
+
+type watchRequest struct {
+	TopicName         string   `json:"topicName"`
+	LabelIds          []string `json:"labelIds,omitempty"`
+	LabelFilterAction string   `json:"labelFilterAction,omitempty"`
+}
+
+type watchResponse struct {
+	HistoryId  string `json:"historyId"`
+	Expiration string `json:"expiration"`
+}
+
+type profile struct {
+	EmailAddress string `json:"emailAddress"`
+	HistoryId    string `json:"historyId"`
+}
+
+type message struct {
+	Id       string   `json:"id"`
+	ThreadId string   `json:"threadId"`
+	LabelIds []string `json:"labelIds"`
+}
+
+type messageChange struct {
+	Message  message  `json:"message"`
+	LabelIds []string `json:"labelIds"`
+}
+
+type history struct {
+	Id              string          `json:"id"`
+	MessagesAdded   []messageChange `json:"messagesAdded"`
+	MessagesDeleted []messageChange `json:"messagesDeleted"`
+	LabelsAdded     []messageChange `json:"labelsAdded"`
+	LabelsRemoved   []messageChange `json:"labelsRemoved"`
+}
+
+type historyList struct {
+	History       []history `json:"history"`
+	NextPageToken string    `json:"nextPageToken"`
+	HistoryId     string    `json:"historyId"`
+}
+
+// notificationData is the payload of the notifications Gmail publishes to Pub/Sub.
+type notificationData struct {
+	EmailAddress string `json:"emailAddress"`
+	HistoryId    uint64 `json:"historyId"`
+}
+
+// Resources returns a single resource per user, the label IDs to watch, as Gmail supports a single watch per user.
+func (c *client) Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	gmail, ok := source.(*sourcesv1alpha1.GmailSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	labels := append([]string(nil), gmail.Spec.LabelIds...)
+	sort.Strings(labels)
+	return []string{strings.Join(labels, labelsSeparator)}, nil
+}
+
+// Cursor returns the current history ID of the mailbox.
+func (c *client) Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error) {
+	var p profile
+	if err := c.do(ctx, http.MethodGet, "profile", nil, &p); err != nil {
+		return "", err
+	}
+	return p.HistoryId, nil
+}
+
+// Watch creates the push subscription of the user to the topic of the source, and then watches the mailbox.
+// The subscription name is the same on every renewal and on topic changes within the same project, so the
+// renewed channel replaces the current one.
+func (c *client) Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, channel *gsuite.Channel) (*gsuite.Channel, error) {
+	gmail, ok := source.(*sourcesv1alpha1.GmailSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	name, err := c.subscriptionName(gmail)
+	if err != nil {
+		return nil, err
+	}
+	// Pub/Sub cannot send the channel token in a header.
+	endpoint := channel.Address + "?" + url.Values{gsuite.QueryChannelToken: {channel.Token}}.Encode()
+	if err := c.pubsub.push(ctx, name, gmail.Spec.Topic, endpoint); err != nil {
+		return nil, err
+	}
+
+	req := &watchRequest{TopicName: gmail.Spec.Topic}
+	if resource != "" {
+		req.LabelIds = strings.Split(resource, labelsSeparator)
+		req.LabelFilterAction = "include"
+	}
+	var resp watchResponse
+	if err := c.do(ctx, http.MethodPost, "watch", req, &resp); err != nil {
+		return nil, err
+	}
+	expiration, err := strconv.ParseInt(resp.Expiration, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid watch expiration %q: %v", resp.Expiration, err)
+	}
+	return &gsuite.Channel{
+		Id:         name,
+		ResourceId: gmail.Spec.Topic,
+		Token:      channel.Token,
+		Address:    channel.Address,
+		Expiration: gsuite.MillisToTime(expiration),
+	}, nil
+}
+
+// Stop deletes the push subscription of the user, and then stops watching the mailbox, unless another
+// source still has a subscription for the user in the same project, as Gmail supports a single watch per user.
+func (c *client) Stop(ctx context.Context, channel *gsuite.Channel) error {
+	if err := c.pubsub.delete(ctx, channel.Id); err != nil {
+		return err
+	}
+	parts := strings.Split(channel.Id, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "subscriptions" {
+		return fmt.Errorf("invalid subscription %q", channel.Id)
+	}
+	names, err := c.pubsub.subscriptions(ctx, parts[1])
+	if err != nil {
+		return err
+	}
+	suffix := "-" + c.userHash()
+	for _, name := range names {
+		if name != channel.Id && strings.Contains(name, "/subscriptions/gmail-") && strings.HasSuffix(name, suffix) {
+			return nil
+		}
+	}
+	return c.do(ctx, http.MethodPost, "stop", nil, nil)
+}
+
+// StopReplaced deletes the push subscription of a channel replaced by one to a topic in another project,
+// as the watch of the mailbox now publishes to the new topic.
+func (c *client) StopReplaced(ctx context.Context, channel *gsuite.Channel) error {
+	return c.pubsub.delete(ctx, channel.Id)
+}
+
+// Events lists the mailbox history from the history ID cursor, and returns an event for each change
+// to the messages with the watched labels, along with the current history ID.
+func (c *client) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	// Every subscription to the topic gets the notifications of all the users, so we skip the ones of the others.
+	if len(notification.Data) > 0 {
+		var data notificationData
+		if err := json.Unmarshal(notification.Data, &data); err != nil {
+			return nil, "", fmt.Errorf("invalid notification data: %v", err)
+		}
+		if !strings.EqualFold(data.EmailAddress, c.email) {
+			return nil, cursor, nil
+		}
+	}
+
+	var labels []string
+	if notification.Resource != "" {
+		labels = strings.Split(notification.Resource, labelsSeparator)
+	}
+
+	var events []cloudevents.Event
+	pageToken := ""
+	for {
+		params := url.Values{}
+		params.Set("startHistoryId", cursor)
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		var list historyList
+		err := c.do(ctx, http.MethodGet, "history?"+params.Encode(), nil, &list)
+		if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+			// The history ID is too old, so we start over from the current one, as the mailbox cannot be resynced.
+			newCursor, err := c.Cursor(ctx, nil, notification.Resource)
+			return nil, newCursor, err
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list history from history ID %q: %v", cursor, err)
+		}
+		for _, h := range list.History {
+			events = append(events, c.newEvents(h.MessagesAdded, sourcesv1alpha1.GmailSourceMessageAddedEventType, h.Id, labels, notification)...)
+			events = append(events, c.newEvents(h.MessagesDeleted, sourcesv1alpha1.GmailSourceMessageDeletedEventType, h.Id, labels, notification)...)
+			events = append(events, c.newEvents(h.LabelsAdded, sourcesv1alpha1.GmailSourceLabelAddedEventType, h.Id, labels, notification)...)
+			events = append(events, c.newEvents(h.LabelsRemoved, sourcesv1alpha1.GmailSourceLabelRemovedEventType, h.Id, labels, notification)...)
+		}
+		if list.NextPageToken == "" {
+			return events, list.HistoryId, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// newEvents returns the events of the given type for the changes to the messages with any of the given labels.
+func (c *client) newEvents(changes []messageChange, eventType, historyId string, labels []string, notification *gsuite.Notification) []cloudevents.Event {
+	var events []cloudevents.Event
+	for _, change := range changes {
+		if !hasAnyLabel(change, labels) {
+			continue
+		}
+		data := &Change{
+			MessageId:       change.Message.Id,
+			ThreadId:        change.Message.ThreadId,
+			LabelIds:        change.Message.LabelIds,
+			ChangedLabelIds: change.LabelIds,
+			HistoryId:       historyId,
+		}
+		id := fmt.Sprintf("%s-%s-%s", historyId, change.Message.Id, strings.TrimPrefix(eventType, sourcesv1alpha1.GmailSourceEventType+"."))
+		source := fmt.Sprintf(historyURL, url.PathEscape(c.email))
+		events = append(events, gsuite.NewEvent(id, eventType, source, change.Message.Id, "", data, notification))
+	}
+	return events
+}
+
+// hasAnyLabel returns true if the message or the labels changed have any of the given labels.
+// The messages without labels, e.g., some deleted ones, are always kept.
+func hasAnyLabel(change messageChange, labels []string) bool {
+	if len(labels) == 0 || len(change.Message.LabelIds) == 0 {
+		return true
+	}
+	for _, label := range labels {
+		if contains(change.Message.LabelIds, label) || contains(change.LabelIds, label) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionName returns the name of the push subscription of the user, in the project of the topic.
+func (c *client) subscriptionName(source *sourcesv1alpha1.GmailSource) (string, error) {
+	parts := strings.Split(source.Spec.Topic, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "topics" {
+		return "", fmt.Errorf("invalid topic %q", source.Spec.Topic)
+	}
+	return fmt.Sprintf("projects/%s/subscriptions/gmail-%s-%s", parts[1], source.UID, c.userHash()), nil
+}
+
+// userHash identifies the user in the names of its subscriptions, without leaking the email address.
+func (c *client) userHash() string {
+	hash := sha256.Sum256([]byte(strings.ToLower(c.email)))
+	return hex.EncodeToString(hash[:8])
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return gsuite.DoJSON(c.gmail, req.WithContext(ctx), v)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmail
+
+import (
+	"context"
+	"testing"
+
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	testUser  = "user@example.com"
+	testTopic = "projects/project-1/topics/gmail"
+)
+
+func newTestClient(t *testing.T, api *gstesting.GoogleAPI, email string) gsuite.Client {
+	t.Helper()
+	kind := Kind{Endpoint: api.GmailEndpoint(), PubsubEndpoint: api.PubsubEndpoint(), HTTPClient: api.Client()}
+	c, err := kind.NewClient(context.Background(), []byte(gstesting.Credentials), email)
+	if err != nil {
+		t.Fatalf("NewClient() = %v", err)
+	}
+	return c
+}
+
+func newTestSource(uid, topic string) *sourcesv1alpha1.GmailSource {
+	return &sourcesv1alpha1.GmailSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "source-" + uid, UID: types.UID(uid)},
+		Spec:       sourcesv1alpha1.GmailSourceSpec{Topic: topic},
+	}
+}
+
+func watch(t *testing.T, c gsuite.Client, source *sourcesv1alpha1.GmailSource) *gsuite.Channel {
+	t.Helper()
+	channel, err := c.Watch(context.Background(), source, "", gstesting.HistoryId, &gsuite.Channel{Token: "token", Address: "https://adapter.example.com/user/"})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	return channel
+}
+
+func TestWatch(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api, testUser)
+
+	channel := watch(t, c, newTestSource("uid-1", testTopic))
+	subscriptions := api.Subscriptions()
+	if len(subscriptions) != 1 || subscriptions[0].Name != channel.Id || subscriptions[0].Topic != testTopic {
+		t.Fatalf("Subscriptions() = %+v, want %s to %s", subscriptions, channel.Id, testTopic)
+	}
+	if want := "https://adapter.example.com/user/?token=token"; subscriptions[0].PushEndpoint != want {
+		t.Errorf("push endpoint = %q, want %q", subscriptions[0].PushEndpoint, want)
+	}
+	if n := len(api.Requests(gstesting.GmailUsersWatch)); n != 1 {
+		t.Errorf("got %d users.watch requests, want 1", n)
+	}
+
+	// The renewals update the push endpoint of the same subscription.
+	renewed, err := c.Watch(context.Background(), newTestSource("uid-1", testTopic), "", gstesting.HistoryId, &gsuite.Channel{Token: "token-2", Address: "https://adapter.example.com/user/"})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	if renewed.Id != channel.Id {
+		t.Errorf("renewed subscription = %q, want %q", renewed.Id, channel.Id)
+	}
+	if subscriptions := api.Subscriptions(); len(subscriptions) != 1 || subscriptions[0].PushEndpoint != "https://adapter.example.com/user/?token=token-2" {
+		t.Errorf("Subscriptions() = %+v, want the push endpoint with the renewed token", subscriptions)
+	}
+}
+
+func TestStopKeepsTheWatchOfOtherSources(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api, testUser)
+	ctx := context.Background()
+
+	first := watch(t, c, newTestSource("uid-1", testTopic))
+	second := watch(t, c, newTestSource("uid-2", testTopic))
+	// The subscription of another user must not keep the watch of this one.
+	watch(t, newTestClient(t, api, "other@example.com"), newTestSource("uid-1", testTopic))
+
+	if err := c.Stop(ctx, first); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if n := len(api.Requests(gstesting.GmailUsersStop)); n != 0 {
+		t.Errorf("got %d users.stop requests while another source watches the user, want 0", n)
+	}
+	if n := len(api.Subscriptions()); n != 2 {
+		t.Errorf("got %d subscriptions, want 2", n)
+	}
+
+	if err := c.Stop(ctx, second); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if n := len(api.Requests(gstesting.GmailUsersStop)); n != 1 {
+		t.Errorf("got %d users.stop requests after stopping the last source, want 1", n)
+	}
+	if subscriptions := api.Subscriptions(); len(subscriptions) != 1 || subscriptions[0].Name == second.Id {
+		t.Errorf("Subscriptions() = %+v, want only the one of the other user", subscriptions)
+	}
+
+	// Stopping a deleted subscription, e.g., on a retry, stops the watch again.
+	if err := c.Stop(ctx, second); err != nil {
+		t.Errorf("Stop() = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// pubsubScope is the Pub/Sub API scope needed to manage the push subscriptions.
+	pubsubScope = "https://www.googleapis.com/auth/pubsub"
+	pubsubURL   = "https://pubsub.googleapis.com/v1/"
+	// pubsubEmulatorHostEnv is the environment variable with the host of the local Pub/Sub emulator to use, if any.
+	pubsubEmulatorHostEnv = "PUBSUB_EMULATOR_HOST"
+	// ackDeadlineSeconds is how long Pub/Sub waits for the receive adapter to acknowledge a notification.
+	ackDeadlineSeconds = 60
+)
+
+type pushConfig struct {
+	PushEndpoint string `json:"pushEndpoint"`
+}
+
+type subscription struct {
+	Name               string     `json:"name,omitempty"`
+	Topic              string     `json:"topic"`
+	PushConfig         pushConfig `json:"pushConfig"`
+	AckDeadlineSeconds int        `json:"ackDeadlineSeconds"`
+}
+
+type subscriptionList struct {
+	Subscriptions []subscription `json:"subscriptions"`
+	NextPageToken string         `json:"nextPageToken"`
+}
+
+// pubsub manages the Pub/Sub push subscriptions that deliver the Gmail notifications to the receive adapter.
+type pubsub struct {
+	client  *http.Client
+	baseURL string
+}
+
+// newPubsub returns a Pub/Sub client authenticated as the service account, or one for the
+// local Pub/Sub emulator if PUBSUB_EMULATOR_HOST is set.
+func newPubsub(ctx context.Context, credentials []byte) (*pubsub, error) {
+	if host := os.Getenv(pubsubEmulatorHostEnv); host != "" {
+		return &pubsub{client: http.DefaultClient, baseURL: fmt.Sprintf("http://%s/v1/", host)}, nil
+	}
+	client, err := gsuite.NewHTTPClient(ctx, credentials, "", pubsubScope)
+	if err != nil {
+		return nil, err
+	}
+	return &pubsub{client: client, baseURL: pubsubURL}, nil
+}
+
+// push creates a push subscription to the topic with the given name, or updates it if it exists.
+func (p *pubsub) push(ctx context.Context, name, topic, endpoint string) error {
+	s := &subscription{
+		Topic:              topic,
+		PushConfig:         pushConfig{PushEndpoint: endpoint},
+		AckDeadlineSeconds: ackDeadlineSeconds,
+	}
+	err := p.do(ctx, http.MethodPut, name, s, nil)
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusConflict {
+		err = p.update(ctx, name, s)
+	}
+	return err
+}
+
+// update updates the push endpoint of the existing subscription with the given name, or creates it again
+// if it is subscribed to another topic, as the topic of a subscription cannot be changed.
+func (p *pubsub) update(ctx context.Context, name string, s *subscription) error {
+	var current subscription
+	if err := p.do(ctx, http.MethodGet, name, nil, &current); err != nil {
+		return err
+	}
+	if current.Topic == s.Topic {
+		return p.do(ctx, http.MethodPost, name+":modifyPushConfig", map[string]interface{}{
+			"pushConfig": s.PushConfig,
+		}, nil)
+	}
+	if err := p.delete(ctx, name); err != nil {
+		return err
+	}
+	return p.do(ctx, http.MethodPut, name, s, nil)
+}
+
+// delete deletes the subscription with the given name, if it exists.
+func (p *pubsub) delete(ctx context.Context, name string) error {
+	err := p.do(ctx, http.MethodDelete, name, nil, nil)
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// subscriptions returns the names of the subscriptions in the given project.
+func (p *pubsub) subscriptions(ctx context.Context, project string) ([]string, error) {
+	var names []string
+	pageToken := ""
+	for {
+		path := "projects/" + project + "/subscriptions"
+		if pageToken != "" {
+			path += "?" + url.Values{"pageToken": {pageToken}}.Encode()
+		}
+		var list subscriptionList
+		if err := p.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+			return nil, err
+		}
+		for _, s := range list.Subscriptions {
+			names = append(names, s.Name)
+		}
+		if list.NextPageToken == "" {
+			return names, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+func (p *pubsub) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, p.baseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return gsuite.DoJSON(p.client, req.WithContext(ctx), v)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+	"google.golang.org/api/googleapi"
+)
+
+func TestSubscriptions(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	testSubscriptions(t, &pubsub{client: api.Client(), baseURL: api.PubsubEndpoint()}, "project-1")
+}
+
+// TestSubscriptionsEmulator runs against the local Pub/Sub emulator, e.g., started with
+// gcloud beta emulators pubsub start, and is skipped unless PUBSUB_EMULATOR_HOST is set.
+func TestSubscriptionsEmulator(t *testing.T) {
+	if os.Getenv(pubsubEmulatorHostEnv) == "" {
+		t.Skipf("%s is not set", pubsubEmulatorHostEnv)
+	}
+	ctx := context.Background()
+	p, err := newPubsub(ctx, nil)
+	if err != nil {
+		t.Fatalf("newPubsub() = %v", err)
+	}
+	// The emulator keeps its state, so each run uses its own project.
+	project := fmt.Sprintf("gsuite-source-%d", time.Now().UnixNano())
+	for _, topic := range []string{"gmail", "gmail-2"} {
+		if err := p.do(ctx, http.MethodPut, "projects/"+project+"/topics/"+topic, map[string]interface{}{}, nil); err != nil {
+			t.Fatalf("failed to create topic %q: %v", topic, err)
+		}
+	}
+	testSubscriptions(t, p, project)
+}
+
+// testSubscriptions creates, updates, lists and deletes a push subscription to the gmail topic of the project,
+// and moves it to the gmail-2 topic.
+func testSubscriptions(t *testing.T, p *pubsub, project string) {
+	t.Helper()
+	ctx := context.Background()
+	topic := "projects/" + project + "/topics/gmail"
+	name := "projects/" + project + "/subscriptions/gmail-uid-1-0123456789abcdef"
+
+	if err := p.push(ctx, name, topic, "https://adapter.example.com/user/?token=1"); err != nil {
+		t.Fatalf("push() = %v", err)
+	}
+	if err := p.push(ctx, name, topic, "https://adapter.example.com/user/?token=2"); err != nil {
+		t.Fatalf("push() on an existing subscription = %v", err)
+	}
+	var got subscription
+	if err := p.do(ctx, http.MethodGet, name, nil, &got); err != nil {
+		t.Fatalf("failed to get the subscription: %v", err)
+	}
+	if got.Topic != topic || got.PushConfig.PushEndpoint != "https://adapter.example.com/user/?token=2" {
+		t.Errorf("subscription = %+v, want the updated push endpoint to %s", got, topic)
+	}
+
+	newTopic := "projects/" + project + "/topics/gmail-2"
+	if err := p.push(ctx, name, newTopic, "https://adapter.example.com/user/?token=2"); err != nil {
+		t.Fatalf("push() to another topic = %v", err)
+	}
+	if err := p.do(ctx, http.MethodGet, name, nil, &got); err != nil {
+		t.Fatalf("failed to get the subscription: %v", err)
+	}
+	if got.Topic != newTopic {
+		t.Errorf("subscription topic = %q, want %q", got.Topic, newTopic)
+	}
+
+	names, err := p.subscriptions(ctx, project)
+	if err != nil {
+		t.Fatalf("subscriptions() = %v", err)
+	}
+	if len(names) != 1 || names[0] != name {
+		t.Errorf("subscriptions() = %v, want [%s]", names, name)
+	}
+
+	if err := p.delete(ctx, name); err != nil {
+		t.Fatalf("delete() = %v", err)
+	}
+	if err := p.do(ctx, http.MethodGet, name, nil, nil); err == nil {
+		t.Errorf("got the deleted subscription")
+	} else if e, ok := err.(*googleapi.Error); !ok || e.Code != http.StatusNotFound {
+		t.Errorf("get of the deleted subscription = %v, want a 404", err)
+	}
+	if err := p.delete(ctx, name); err != nil {
+		t.Errorf("delete() of a deleted subscription = %v", err)
+	}
+}
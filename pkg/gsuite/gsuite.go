@@ -0,0 +1,242 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gsuite defines the interface each G Suite product implements to be
+// plugged into the generic reconciler and receive adapter.
+package gsuite
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	"github.com/cloudevents/sdk-go/pkg/cloudevents/types"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// ResourceStatePoll is the resource state of the notifications of the polled resources.
+	ResourceStatePoll = "poll"
+	// ResourceStateSync is the resource state of the notification sent when a channel is created.
+	ResourceStateSync = "sync"
+
+	// HeaderMessageNumber is the push notification header, without the X- prefix,
+	// with the number of the notification in the channel.
+	HeaderMessageNumber = "Goog-Message-Number"
+	// HeaderResourceID is the push notification header, without the X- prefix,
+	// that identifies the watched resource.
+	HeaderResourceID = "Goog-Resource-ID"
+	// ExtensionUser is the CloudEvent extension with the email address of the watched user.
+	ExtensionUser = "user"
+	// QueryChannelToken is the query parameter with the channel token of the notifications
+	// delivered through Pub/Sub, which cannot set the channel token header.
+	QueryChannelToken = "token"
+)
+
+// Kind is implemented by each G Suite product, e.g., Drive or Calendar.
+type Kind interface {
+	// Name is the lowercase name of the product, e.g., "drive".
+	Name() string
+	// NewSource returns an empty source of this kind.
+	NewSource() sourcesv1alpha1.GSuiteSource
+	// ChannelExpiration is the expiration requested when creating channels.
+	// Google might grant a shorter one, so the one from the response is always used.
+	ChannelExpiration() time.Duration
+	// ChannelRenewalPeriod is how long before expiring channels are renewed.
+	ChannelRenewalPeriod() time.Duration
+	// NewClient builds the API client of the product, authenticating with the service account
+	// credentials and impersonating the user with the given email, if any.
+	NewClient(ctx context.Context, credentials []byte, email string) (Client, error)
+}
+
+// PollingKind is implemented by the kinds whose changes can be read from the cursors of the resources alone,
+// e.g., with the Drive changes.list, so that their sources can run in poll mode, without push notifications.
+type PollingKind interface {
+	Kind
+	// SupportsPolling returns true if the sources of the kind can run in poll mode.
+	SupportsPolling() bool
+}
+
+// WatchParamsKind is implemented by the kinds whose channels are created with fields of the source
+// besides the user and the resource, e.g., the Gmail Pub/Sub topic, so that the channels are
+// created again when those change.
+type WatchParamsKind interface {
+	Kind
+	// WatchParams returns the fields of the source the channels are created with, in any format.
+	WatchParams(source sourcesv1alpha1.GSuiteSource) string
+}
+
+// Client is the API client of a G Suite product, impersonating a user.
+type Client interface {
+	// Resources returns the resources of the user the source watches, e.g., calendar IDs.
+	// The kinds that watch a single resource per user return a single empty resource.
+	Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error)
+	// Cursor returns the position from where the receive adapter starts reading
+	// the changes of the resource, e.g., the Drive Changes start page token.
+	Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error)
+	// Watch creates a push notification channel to watch the resource from the given cursor.
+	Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, channel *Channel) (*Channel, error)
+	// Stop stops a push notification channel.
+	Stop(ctx context.Context, channel *Channel) error
+	// Events turns a push notification into the CloudEvents to send, reading the changes after cursor.
+	// It also returns the cursor to read from on the next notification.
+	Events(ctx context.Context, cursor string, notification *Notification) ([]cloudevents.Event, string, error)
+}
+
+// Poller is implemented by the clients of the kinds with resources that cannot be watched with
+// push notifications, e.g., the members of a group. The receive adapter polls their events instead.
+type Poller interface {
+	// PollInterval returns how often the receive adapter reads the events of the resource,
+	// or zero if the resource is watched with push notifications.
+	PollInterval(resource string) time.Duration
+}
+
+// ReplacedStopper is implemented by the clients whose channels share state with the channels that replace them,
+// e.g., the single Gmail watch of a user, which outlives the subscription to the previous topic.
+type ReplacedStopper interface {
+	// StopReplaced stops a channel replaced by a new one of the same user and resource,
+	// without stopping what the new one shares with it.
+	StopReplaced(ctx context.Context, channel *Channel) error
+}
+
+// Watch is a resource of a user watched by a receive adapter. The cursor of the resource is read from,
+// and saved to, the cursors ConfigMap of the source, and its channel is read from the channel tokens
+// secret of the source, both under the CursorKey of the resource.
+type Watch struct {
+	EmailAddress string `json:"emailAddress,omitempty"`
+	Resource     string `json:"resource,omitempty"`
+}
+
+// CursorKey returns the key of the given resource of the given user in the cursors ConfigMap, and in the
+// channels of the channel tokens secret, of a source. It only has the characters allowed in ConfigMap keys.
+func CursorKey(email, resource string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(email + "/" + resource))
+}
+
+// Channel is a push notification channel.
+type Channel struct {
+	Id         string
+	ResourceId string
+	Token      string
+	Address    string
+	Expiration time.Time
+}
+
+// Notification is a push notification received from G Suite.
+type Notification struct {
+	// User is the email address of the watched user, empty if no user is impersonated.
+	User string
+	// Resource is the watched resource of the user, e.g., a calendar ID.
+	Resource  string
+	ChannelId string
+	// MessageNumber is the number of the notification in the channel. Google redelivers a notification
+	// with the same number, and the numbers of the following ones increase, though not one by one.
+	MessageNumber string
+	ResourceId    string
+	ResourceURI   string
+	ResourceState string
+	// Changed are the kinds of changes of an update, only set by some products.
+	Changed []string
+	// Data is the payload of the notification, if any, e.g., a Reports activity, or the Pub/Sub
+	// message of a Gmail notification.
+	Data []byte
+}
+
+// NewHTTPClient returns an HTTP client that authenticates with the service account credentials
+// and impersonates the user with the given email, if any.
+func NewHTTPClient(ctx context.Context, credentials []byte, email string, scope ...string) (*http.Client, error) {
+	// Doing this as there is no way to impersonate a particular user
+	// using the GOOGLE_APPLICATION_CREDENTIALS env variable.
+	conf, err := google.JWTConfigFromJSON(credentials, scope...)
+	if err != nil {
+		return nil, err
+	}
+	// Impersonate the following user using the service account credentials
+	conf.Subject = email
+	return conf.Client(ctx), nil
+}
+
+// NewEvent returns a CloudEvent of the given type and source for a push notification, about the given subject,
+// e.g., a file ID. The event time is taken from t, formatted as RFC 3339, if possible, or it is the current time.
+// The event is built with the richest context the CloudEvents SDK supports, and the receive adapter converts
+// it to the spec version of the source.
+func NewEvent(id, eventType, source, subject, t string, data interface{}, notification *Notification) cloudevents.Event {
+	extensions := make(map[string]interface{})
+	if notification.ResourceId != "" {
+		extensions[HeaderResourceID] = notification.ResourceId
+	}
+	if notification.User != "" {
+		extensions[ExtensionUser] = notification.User
+	}
+	eventTime := types.ParseTimestamp(t)
+	if eventTime == nil {
+		eventTime = &types.Timestamp{Time: time.Now().UTC()}
+	}
+	eventContext := cloudevents.EventContextV03{
+		ID:              id,
+		Type:            eventType,
+		Source:          *types.ParseURLRef(source),
+		Time:            eventTime,
+		DataContentType: cloudevents.StringOfApplicationJSON(),
+		Extensions:      extensions,
+	}
+	if subject != "" {
+		eventContext.Subject = &subject
+	}
+
+	return cloudevents.Event{
+		Context: eventContext.AsV03(),
+		Data:    data,
+	}
+}
+
+// MillisToTime converts a Unix timestamp in milliseconds, as used by the channels, to a Time.
+func MillisToTime(millis int64) time.Time {
+	if millis <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
+
+// TimeToMillis converts a Time to a Unix timestamp in milliseconds, as used by the channels.
+func TimeToMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// DoJSON sends the request and decodes the JSON response into v, if not nil.
+// It is used for the G Suite APIs the vendored Google API clients do not support.
+func DoJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(resp)
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
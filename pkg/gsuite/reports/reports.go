@@ -0,0 +1,250 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reports implements the G Suite kind for the Admin SDK Reports API activities.
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+)
+
+const (
+	// reportsAuditScope is the Reports API scope needed to watch the activities.
+	reportsAuditScope = "https://www.googleapis.com/auth/admin.reports.audit.readonly"
+	// adminURL is the base URL of the Admin SDK APIs.
+	adminURL = "https://www.googleapis.com/admin/"
+	// watchPath is the path of the Reports API activities.watch endpoint, for a user key and an application.
+	watchPath = "reports/v1/activity/users/%s/applications/%s/watch"
+	// stopPath is the path of the Reports API channels.stop endpoint.
+	stopPath = "reports_v1/channels/stop"
+)
+
+// Activity is the data of the CloudEvent sent for each event of an activity.
+type Activity struct {
+	Id          ActivityId `json:"id"`
+	Actor       Actor      `json:"actor"`
+	IpAddress   string     `json:"ipAddress,omitempty"`
+	OwnerDomain string     `json:"ownerDomain,omitempty"`
+	// Event is the activity event, with its type, name and parameters.
+	Event Event `json:"event"`
+}
+
+type ActivityId struct {
+	Time            string `json:"time"`
+	UniqueQualifier string `json:"uniqueQualifier"`
+	ApplicationName string `json:"applicationName"`
+	CustomerId      string `json:"customerId,omitempty"`
+}
+
+type Actor struct {
+	Email      string `json:"email,omitempty"`
+	ProfileId  string `json:"profileId,omitempty"`
+	CallerType string `json:"callerType,omitempty"`
+	Key        string `json:"key,omitempty"`
+}
+
+type Event struct {
+	Type       string            `json:"type,omitempty"`
+	Name       string            `json:"name"`
+	Parameters []json.RawMessage `json:"parameters,omitempty"`
+}
+
+// activity is the payload of the push notifications, an activity with all its events.
+type activity struct {
+	Id          ActivityId `json:"id"`
+	Actor       Actor      `json:"actor"`
+	IpAddress   string     `json:"ipAddress"`
+	OwnerDomain string     `json:"ownerDomain"`
+	Events      []Event    `json:"events"`
+}
+
+// Kind is the Reports G Suite kind.
+type Kind struct {
+	// Endpoint overrides the base URL of the Admin SDK APIs, and HTTPClient the authenticated client,
+	// e.g., to point them at a fake server in tests.
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+var _ gsuite.WatchParamsKind = Kind{}
+
+func (Kind) Name() string {
+	return "reports"
+}
+
+func (Kind) NewSource() sourcesv1alpha1.GSuiteSource {
+	return &sourcesv1alpha1.ReportsSource{}
+}
+
+// ChannelExpiration is six hours, the maximum allowed by the Reports API.
+func (Kind) ChannelExpiration() time.Duration {
+	return 6 * time.Hour
+}
+
+func (Kind) ChannelRenewalPeriod() time.Duration {
+	return time.Hour
+}
+
+// WatchParams is the user whose activities are watched.
+func (Kind) WatchParams(source sourcesv1alpha1.GSuiteSource) string {
+	if reports, ok := source.(*sourcesv1alpha1.ReportsSource); ok {
+		return reports.Spec.UserKey
+	}
+	return ""
+}
+
+// NewClient returns a Reports client impersonating the given G Suite admin.
+func (k Kind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	c := &client{http: k.HTTPClient, baseURL: k.Endpoint}
+	if c.http == nil {
+		var err error
+		if c.http, err = gsuite.NewHTTPClient(ctx, credentials, email, reportsAuditScope); err != nil {
+			return nil, err
+		}
+	}
+	if c.baseURL == "" {
+		c.baseURL = adminURL
+	}
+	return c, nil
+}
+
+type client struct {
+	http    *http.Client
+	baseURL string
+}
+
+type channel struct {
+	Id         string `json:"id"`
+	ResourceId string `json:"resourceId,omitempty"`
+	Token      string `json:"token,omitempty"`
+	Address    string `json:"address,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Expiration string `json:"expiration,omitempty"`
+}
+
+// Resources returns the applications whose activities are watched, with one channel each.
+func (c *client) Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	reports, ok := source.(*sourcesv1alpha1.ReportsSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	return reports.Spec.Applications, nil
+}
+
+// Cursor returns an empty cursor, as the activities are sent in the push notifications.
+func (c *client) Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error) {
+	return "", nil
+}
+
+// Watch watches the activities of the application resource.
+func (c *client) Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, ch *gsuite.Channel) (*gsuite.Channel, error) {
+	reports, ok := source.(*sourcesv1alpha1.ReportsSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	userKey := reports.Spec.UserKey
+	if userKey == "" {
+		userKey = sourcesv1alpha1.ReportsSourceAllUsers
+	}
+	req := &channel{
+		Id:         ch.Id,
+		Token:      ch.Token,
+		Address:    ch.Address,
+		Type:       "web_hook",
+		Expiration: strconv.FormatInt(gsuite.TimeToMillis(ch.Expiration), 10),
+	}
+	var resp channel
+	if err := c.do(ctx, fmt.Sprintf(watchPath, url.PathEscape(userKey), url.PathEscape(resource)), req, &resp); err != nil {
+		return nil, err
+	}
+	watched := &gsuite.Channel{
+		Id:         resp.Id,
+		ResourceId: resp.ResourceId,
+		Token:      ch.Token,
+		Address:    ch.Address,
+	}
+	if resp.Expiration != "" {
+		expiration, err := strconv.ParseInt(resp.Expiration, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel expiration %q: %v", resp.Expiration, err)
+		}
+		watched.Expiration = gsuite.MillisToTime(expiration)
+	}
+	return watched, nil
+}
+
+// Stop stops the channel.
+func (c *client) Stop(ctx context.Context, ch *gsuite.Channel) error {
+	return c.do(ctx, stopPath, &channel{Id: ch.Id, ResourceId: ch.ResourceId}, nil)
+}
+
+// Events returns an event per event of the activity in the push notification,
+// with type org.nachocano.source.gsuite.reports.<application>.<event name>.
+func (c *client) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	var a activity
+	if err := json.Unmarshal(notification.Data, &a); err != nil {
+		return nil, "", fmt.Errorf("invalid activity: %v", err)
+	}
+	application := a.Id.ApplicationName
+	if application == "" {
+		application = notification.Resource
+	}
+
+	// The subject of the events is the user who performed the activity.
+	actor := a.Actor.Email
+	if actor == "" {
+		actor = a.Actor.ProfileId
+	}
+
+	events := make([]cloudevents.Event, 0, len(a.Events))
+	for i, event := range a.Events {
+		data := &Activity{
+			Id:          a.Id,
+			Actor:       a.Actor,
+			IpAddress:   a.IpAddress,
+			OwnerDomain: a.OwnerDomain,
+			Event:       event,
+		}
+		id := fmt.Sprintf("%s-%s-%d", a.Id.Time, a.Id.UniqueQualifier, i)
+		eventType := fmt.Sprintf("%s.%s.%s", sourcesv1alpha1.ReportsSourceEventType, application, event.Name)
+		events = append(events, gsuite.NewEvent(id, eventType, notification.ResourceURI, actor, a.Id.Time, data, notification))
+	}
+	return events, cursor, nil
+}
+
+func (c *client) do(ctx context.Context, path string, body, v interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return gsuite.DoJSON(c.http, req.WithContext(ctx), v)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reports
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+)
+
+const (
+	testAdmin  = "admin@example.com"
+	testApp    = "login"
+	testTime   = "2019-05-08T21:03:41.927Z"
+	testUnique = "-4209470234529842"
+
+	// testActivity is the payload of a Reports push notification, an activity with two events.
+	testActivity = `{
+  "kind": "admin#reports#activity",
+  "id": {
+    "time": "` + testTime + `",
+    "uniqueQualifier": "` + testUnique + `",
+    "applicationName": "` + testApp + `",
+    "customerId": "C01"
+  },
+  "actor": {"callerType": "USER", "email": "jdoe@example.com", "profileId": "1"},
+  "ipAddress": "10.0.0.1",
+  "events": [
+    {"type": "login", "name": "login_success", "parameters": [{"name": "login_type", "value": "google_password"}]},
+    {"type": "login", "name": "login_verification"}
+  ]
+}`
+)
+
+func newTestClient(t *testing.T, api *gstesting.GoogleAPI) gsuite.Client {
+	t.Helper()
+	c, err := Kind{Endpoint: api.AdminEndpoint(), HTTPClient: api.Client()}.NewClient(context.Background(), []byte(gstesting.Credentials), testAdmin)
+	if err != nil {
+		t.Fatalf("NewClient() = %v", err)
+	}
+	return c
+}
+
+func newReportsSource() *sourcesv1alpha1.ReportsSource {
+	return &sourcesv1alpha1.ReportsSource{
+		Spec: sourcesv1alpha1.ReportsSourceSpec{Applications: []string{testApp}},
+	}
+}
+
+func TestWatchAndStop(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	ctx := context.Background()
+	source := newReportsSource()
+
+	resources, err := c.Resources(ctx, source)
+	if err != nil {
+		t.Fatalf("Resources() = %v", err)
+	}
+	if len(resources) != 1 || resources[0] != testApp {
+		t.Fatalf("Resources() = %v, want [%s]", resources, testApp)
+	}
+	channel, err := c.Watch(ctx, source, resources[0], "", &gsuite.Channel{Id: "channel-1", Address: "https://adapter.example.com/"})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	if channel.Id != "channel-1" || channel.ResourceId == "" || channel.Expiration.IsZero() {
+		t.Errorf("Watch() = %+v, want channel-1 with a resource ID and an expiration", channel)
+	}
+	requests := api.Requests(gstesting.ReportsActivitiesWatch)
+	if len(requests) != 1 || requests[0].UserKey != sourcesv1alpha1.ReportsSourceAllUsers || requests[0].Application != testApp {
+		t.Errorf("activities.watch requests = %+v, want one for the %s activities of all the users", requests, testApp)
+	}
+
+	if err := c.Stop(ctx, channel); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if got := len(api.Channels()); got != 0 {
+		t.Errorf("got %d channels after Stop(), want 0", got)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+
+	// The receiver builds the notification from the headers and body of the push notification,
+	// as the receive adapter does, and returns the events of the client.
+	var events []cloudevents.Event
+	var eventsErr error
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			eventsErr = err
+			return
+		}
+		events, _, eventsErr = c.Events(r.Context(), "", &gsuite.Notification{
+			User:          testAdmin,
+			Resource:      testApp,
+			ChannelId:     r.Header.Get("X-Goog-Channel-ID"),
+			MessageNumber: r.Header.Get("X-Goog-Message-Number"),
+			ResourceId:    r.Header.Get("X-Goog-Resource-ID"),
+			ResourceURI:   r.Header.Get("X-Goog-Resource-URI"),
+			ResourceState: r.Header.Get("X-Goog-Resource-State"),
+			Data:          data,
+		})
+	}))
+	defer receiver.Close()
+
+	channel, err := c.Watch(context.Background(), newReportsSource(), testApp, "", &gsuite.Channel{Id: "channel-1", Address: receiver.URL})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	resp, err := api.NotifyChannelWithBody(channel.Id, testApp, []byte(testActivity))
+	if err != nil {
+		t.Fatalf("NotifyChannelWithBody() = %v", err)
+	}
+	resp.Body.Close()
+	if eventsErr != nil {
+		t.Fatalf("Events() = %v", eventsErr)
+	}
+
+	want := []struct {
+		id, eventType string
+	}{{
+		id:        testTime + "-" + testUnique + "-0",
+		eventType: sourcesv1alpha1.ReportsSourceEventType + ".login.login_success",
+	}, {
+		id:        testTime + "-" + testUnique + "-1",
+		eventType: sourcesv1alpha1.ReportsSourceEventType + ".login.login_verification",
+	}}
+	if len(events) != len(want) {
+		t.Fatalf("Events() returned %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.ID() != want[i].id {
+			t.Errorf("event %d ID = %q, want %q", i, event.ID(), want[i].id)
+		}
+		if event.Type() != want[i].eventType {
+			t.Errorf("event %d type = %q, want %q", i, event.Type(), want[i].eventType)
+		}
+		if subject := event.Context.AsV03().Subject; subject == nil || *subject != "jdoe@example.com" {
+			t.Errorf("event %d subject = %v, want jdoe@example.com", i, subject)
+		}
+		if event.Source() != api.Channels()[0].ResourceURI {
+			t.Errorf("event %d source = %q, want %q", i, event.Source(), api.Channels()[0].ResourceURI)
+		}
+		if event.Time().Format("2006-01-02T15:04:05.000Z07:00") != testTime {
+			t.Errorf("event %d time = %v, want %s", i, event.Time(), testTime)
+		}
+	}
+	if data, ok := events[0].Data.(*Activity); !ok || data.IpAddress != "10.0.0.1" || data.Event.Name != "login_success" || len(data.Event.Parameters) != 1 {
+		t.Errorf("event data = %+v, want the login_success event of the activity", events[0].Data)
+	}
+}
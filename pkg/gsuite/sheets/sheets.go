@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sheets implements the G Suite kind for Google Sheets.
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"github.com/nachocano/gsuite-source/pkg/gsuite/drive"
+	gsdrive "google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// Change is the data of the CloudEvent sent for each spreadsheet notification.
+type Change struct {
+	SpreadsheetId string `json:"spreadsheetId"`
+	// State is the resource state of the notification, e.g., update, trash or remove.
+	State string `json:"state"`
+	// Changed are the kinds of changes of an update, e.g., content or permissions.
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Kind is the Sheets G Suite kind.
+type Kind struct {
+	// ClientOptions are appended to the options of the Drive API clients, e.g., to point them at a fake server in tests.
+	ClientOptions []option.ClientOption
+}
+
+var _ gsuite.Kind = Kind{}
+
+func (Kind) Name() string {
+	return "sheets"
+}
+
+func (Kind) NewSource() sourcesv1alpha1.GSuiteSource {
+	return &sourcesv1alpha1.SheetsSource{}
+}
+
+// ChannelExpiration is one day, the maximum allowed by the Drive API for files.
+func (Kind) ChannelExpiration() time.Duration {
+	return 24 * time.Hour
+}
+
+func (Kind) ChannelRenewalPeriod() time.Duration {
+	return time.Hour
+}
+
+// NewClient returns a Drive client, as spreadsheets are watched through the Drive API.
+// If email is empty, the spreadsheet must be shared with the service account.
+func (k Kind) NewClient(ctx context.Context, credentials []byte, email string) (gsuite.Client, error) {
+	httpClient, err := gsuite.NewHTTPClient(ctx, credentials, email, gsdrive.DriveReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]option.ClientOption{option.WithHTTPClient(httpClient)}, k.ClientOptions...)
+	svc, err := gsdrive.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &client{svc: svc}, nil
+}
+
+type client struct {
+	svc *gsdrive.Service
+}
+
+// Resources returns a single resource, the spreadsheet ID.
+func (c *client) Resources(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	sheets, ok := source.(*sourcesv1alpha1.SheetsSource)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source %T", source)
+	}
+	return []string{sheets.Spec.SpreadsheetId}, nil
+}
+
+// Cursor returns an empty cursor, as there is no changes feed to read from.
+func (c *client) Cursor(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource string) (string, error) {
+	return "", nil
+}
+
+// Watch watches the spreadsheet file, which may be in a shared drive.
+func (c *client) Watch(ctx context.Context, source sourcesv1alpha1.GSuiteSource, resource, cursor string, channel *gsuite.Channel) (*gsuite.Channel, error) {
+	resp, err := c.svc.Files.Watch(resource, drive.ToChannel(channel)).Context(ctx).Do(drive.SupportsAllDrives)
+	if err != nil {
+		return nil, err
+	}
+	return drive.FromChannel(resp), nil
+}
+
+func (c *client) Stop(ctx context.Context, channel *gsuite.Channel) error {
+	return c.svc.Channels.Stop(drive.ToChannel(channel)).Context(ctx).Do()
+}
+
+// Events returns a single event per notification. Files notifications do not have payloads,
+// we build ours from the headers. The event ID is the channel ID along with the message number,
+// which is unique per notification of the channel.
+func (c *client) Events(ctx context.Context, cursor string, notification *gsuite.Notification) ([]cloudevents.Event, string, error) {
+	change := &Change{
+		SpreadsheetId: notification.Resource,
+		State:         notification.ResourceState,
+		Changed:       notification.Changed,
+	}
+	id := fmt.Sprintf("%s-%s", notification.ChannelId, notification.MessageNumber)
+	event := gsuite.NewEvent(id, sourcesv1alpha1.SheetsSourceEventType, notification.ResourceURI, notification.Resource, "", change, notification)
+	return []cloudevents.Event{event}, cursor, nil
+}
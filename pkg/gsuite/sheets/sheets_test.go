@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sheets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+)
+
+const testSpreadsheet = "spreadsheet-1"
+
+func newTestClient(t *testing.T, api *gstesting.GoogleAPI) gsuite.Client {
+	t.Helper()
+	c, err := Kind{ClientOptions: api.DriveOptions()}.NewClient(context.Background(), []byte(gstesting.Credentials), "user@example.com")
+	if err != nil {
+		t.Fatalf("NewClient() = %v", err)
+	}
+	return c
+}
+
+// watch watches the test spreadsheet, and returns its channel.
+func watch(t *testing.T, c gsuite.Client) *gsuite.Channel {
+	t.Helper()
+	ctx := context.Background()
+	source := &sourcesv1alpha1.SheetsSource{
+		Spec: sourcesv1alpha1.SheetsSourceSpec{SpreadsheetId: testSpreadsheet},
+	}
+	resources, err := c.Resources(ctx, source)
+	if err != nil {
+		t.Fatalf("Resources() = %v", err)
+	}
+	if len(resources) != 1 || resources[0] != testSpreadsheet {
+		t.Fatalf("Resources() = %v, want [%s]", resources, testSpreadsheet)
+	}
+	channel, err := c.Watch(ctx, source, resources[0], "", &gsuite.Channel{
+		Id:      "channel-1",
+		Token:   "token",
+		Address: "https://adapter.example.com/",
+	})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	return channel
+}
+
+func TestWatchAndStop(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+
+	channel := watch(t, c)
+	if channel.Id != "channel-1" || channel.ResourceId == "" || channel.Expiration.IsZero() {
+		t.Errorf("Watch() = %+v, want channel-1 with a resource ID and an expiration", channel)
+	}
+	requests := api.Requests(gstesting.DriveFilesWatch)
+	if len(requests) != 1 {
+		t.Fatalf("got %d files.watch requests, want 1", len(requests))
+	}
+	if got := requests[0].Query.Get("supportsAllDrives"); got != "true" {
+		t.Errorf("files.watch supportsAllDrives = %q, want true", got)
+	}
+	channels := api.Channels()
+	if len(channels) != 1 || channels[0].FileId != testSpreadsheet {
+		t.Fatalf("Channels() = %+v, want a channel watching %s", channels, testSpreadsheet)
+	}
+
+	if err := c.Stop(context.Background(), channel); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if got := len(api.Channels()); got != 0 {
+		t.Errorf("got %d channels after Stop(), want 0", got)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	c := newTestClient(t, api)
+	channel := watch(t, c)
+	watched := api.Channels()[0]
+
+	notification := &gsuite.Notification{
+		User:          "user@example.com",
+		Resource:      testSpreadsheet,
+		ChannelId:     channel.Id,
+		MessageNumber: "2",
+		ResourceId:    watched.ResourceId,
+		ResourceURI:   watched.ResourceURI,
+		ResourceState: "update",
+		Changed:       []string{"content"},
+	}
+	events, cursor, err := c.Events(context.Background(), "", notification)
+	if err != nil {
+		t.Fatalf("Events() = %v", err)
+	}
+	if cursor != "" {
+		t.Errorf("Events() cursor = %q, want none", cursor)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Events() returned %d events, want 1", len(events))
+	}
+	event := events[0]
+	if want := "channel-1-2"; event.ID() != want {
+		t.Errorf("event ID = %q, want %q", event.ID(), want)
+	}
+	if event.Type() != sourcesv1alpha1.SheetsSourceEventType {
+		t.Errorf("event type = %q, want %q", event.Type(), sourcesv1alpha1.SheetsSourceEventType)
+	}
+	if event.Source() != watched.ResourceURI {
+		t.Errorf("event source = %q, want %q", event.Source(), watched.ResourceURI)
+	}
+	want := &Change{SpreadsheetId: testSpreadsheet, State: "update", Changed: []string{"content"}}
+	if diff := cmp.Diff(want, event.Data); diff != "" {
+		t.Errorf("event data (-want, +got) = %v", diff)
+	}
+}
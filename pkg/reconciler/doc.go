@@ -14,5 +14,5 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package drive implements a DriveSource controller.
-package drive
+// Package reconciler implements a controller for any G Suite source kind.
+package reconciler
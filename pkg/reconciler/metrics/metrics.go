@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the controller.
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/googleapi"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "gsuite"
+
+	// LabelKind is the label with the name of the G Suite product, e.g., "drive".
+	LabelKind = "kind"
+	// LabelNamespace and LabelName are the labels with the namespace and name of a source.
+	LabelNamespace = "namespace"
+	LabelName      = "name"
+	// LabelCode is the label with the HTTP status code of a Google API error.
+	LabelCode = "code"
+	// LabelResult is the label with the result of a reconciliation, i.e., ResultSuccess or ResultError.
+	LabelResult = "result"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	// ActiveChannels is the number of push notification channels of each source.
+	ActiveChannels = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "active_channels",
+		Help:      "Number of push notification channels of a source.",
+	}, []string{LabelKind, LabelNamespace, LabelName})
+
+	// ChannelCreations counts the push notification channels created, excluding the renewals.
+	ChannelCreations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "channel_creations_total",
+		Help:      "Number of push notification channels created.",
+	}, []string{LabelKind})
+
+	// ChannelRenewals counts the push notification channels renewed before they expired.
+	ChannelRenewals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "channel_renewals_total",
+		Help:      "Number of push notification channels renewed.",
+	}, []string{LabelKind})
+
+	// ChannelStops counts the push notification channels stopped.
+	ChannelStops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "channel_stops_total",
+		Help:      "Number of push notification channels stopped.",
+	}, []string{LabelKind})
+
+	// APIErrors counts the errors returned by the Google APIs, by HTTP status code.
+	APIErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "google_api_errors_total",
+		Help:      "Number of errors returned by the Google APIs, by HTTP status code.",
+	}, []string{LabelKind, LabelCode})
+
+	// ReconcileDuration is the duration of the reconciliations of the sources.
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciliations of the sources, in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{LabelKind, LabelResult})
+)
+
+func init() {
+	// The controller manager serves the metrics of its registry.
+	metrics.Registry.MustRegister(
+		ActiveChannels,
+		ChannelCreations,
+		ChannelRenewals,
+		ChannelStops,
+		APIErrors,
+		ReconcileDuration,
+	)
+}
+
+// RecordAPIError counts err if it is an error returned by a Google API.
+func RecordAPIError(kind string, err error) {
+	if gerr, ok := err.(*googleapi.Error); ok {
+		APIErrors.WithLabelValues(kind, strconv.Itoa(gerr.Code)).Inc()
+	}
+}
@@ -0,0 +1,1101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knative/eventing-sources/pkg/controller/sinks"
+	"github.com/knative/pkg/logging"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/controller/sdk"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"github.com/nachocano/gsuite-source/pkg/reconciler/metrics"
+	"github.com/nachocano/gsuite-source/pkg/reconciler/resources"
+	"go.uber.org/zap"
+	"google.golang.org/api/googleapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	ctrlsource "sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// systemNamespaceEnvVar is the environment variable with the namespace the controller runs in.
+	systemNamespaceEnvVar = "SYSTEM_NAMESPACE"
+
+	// tokenBytes is the number of random bytes of the channel tokens.
+	tokenBytes = 32
+
+	// resyncPeriod is how often we list the users of a source and their resources,
+	// e.g., the members of a group or the calendars in a calendar list.
+	resyncPeriod = time.Hour
+)
+
+// Add creates a new Controller for the sources of the given kind and adds it to the
+// Manager with default RBAC. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, logger *zap.SugaredLogger, kind gsuite.Kind) error {
+	// controllerAgentName is the string used by this controller to identify
+	// itself when creating events.
+	controllerAgentName := fmt.Sprintf("%s-source-controller", kind.Name())
+	raImageEnvVar := fmt.Sprintf("%s_RA_IMAGE", strings.ToUpper(kind.Name()))
+
+	receiveAdapterImage, defined := os.LookupEnv(raImageEnvVar)
+	if !defined {
+		return fmt.Errorf("required environment variable %q not defined", raImageEnvVar)
+	}
+	systemNamespace, defined := os.LookupEnv(systemNamespaceEnvVar)
+	if !defined {
+		return fmt.Errorf("required environment variable %q not defined", systemNamespaceEnvVar)
+	}
+	kubeClientSet, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	loggingWatch, err := watchLoggingConfig(mgr, kubeClientSet, systemNamespace, kind)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Adding the %s Source Controller", strings.Title(kind.Name()))
+	p := &sdk.Provider{
+		AgentName: controllerAgentName,
+		Parent:    kind.NewSource(),
+		Owns:      []runtime.Object{&servingv1alpha1.Service{}, &corev1.Secret{}},
+		Watches:   []sdk.Watch{loggingWatch},
+		Reconciler: &reconciler{
+			kind:                kind,
+			finalizerName:       controllerAgentName,
+			recorder:            mgr.GetRecorder(controllerAgentName),
+			scheme:              mgr.GetScheme(),
+			configMaps:          kubeClientSet.CoreV1(),
+			systemNamespace:     systemNamespace,
+			receiveAdapterImage: receiveAdapterImage,
+		},
+	}
+
+	return p.Add(mgr, logger)
+}
+
+// watchLoggingConfig returns a watch of the config-logging ConfigMap in the systemNamespace that enqueues all the
+// sources of the kind, as their receive adapters log with it. Only that ConfigMap is watched, so that the
+// ConfigMaps of the cluster are not cached.
+func watchLoggingConfig(mgr manager.Manager, kubeClientSet kubernetes.Interface, systemNamespace string, kind gsuite.Kind) (sdk.Watch, error) {
+	sources, err := mgr.GetCache().GetInformer(kind.NewSource())
+	if err != nil {
+		return sdk.Watch{}, err
+	}
+	configMaps := toolscache.NewSharedIndexInformer(
+		toolscache.NewListWatchFromClient(kubeClientSet.CoreV1().RESTClient(), "configmaps", systemNamespace,
+			fields.OneTermEqualSelector("metadata.name", resources.LoggingConfigMapName)),
+		&corev1.ConfigMap{}, 0, toolscache.Indexers{})
+	if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		configMaps.Run(stop)
+		return nil
+	})); err != nil {
+		return sdk.Watch{}, err
+	}
+
+	return sdk.Watch{
+		Source: &ctrlsource.Informer{Informer: configMaps},
+		Handler: &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(handler.MapObject) []reconcile.Request {
+				return allRequests(sources.GetStore())
+			}),
+		},
+	}, nil
+}
+
+// allRequests returns a request for each object of the store.
+func allRequests(store toolscache.Store) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, key := range store.ListKeys() {
+		namespace, name, err := toolscache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	}
+	return requests
+}
+
+// reconciler reconciles the G Suite sources of a kind.
+type reconciler struct {
+	kind          gsuite.Kind
+	finalizerName string
+	client        client.Client
+	scheme        *runtime.Scheme
+	recorder      record.EventRecorder
+	// configMaps reads the config-logging ConfigMap from the systemNamespace of the controller, and maintains
+	// the cursors ConfigMaps of the sources, without caching all the ConfigMaps of the cluster.
+	configMaps          corev1client.ConfigMapsGetter
+	systemNamespace     string
+	receiveAdapterImage string
+}
+
+// Reconcile reads that state of the cluster for a G Suite source
+// object and makes changes based on the state read and what is in its Spec.
+func (r *reconciler) Reconcile(ctx context.Context, object runtime.Object) (reconcile.Result, error) {
+	logger := logging.FromContext(ctx)
+
+	source, ok := object.(sourcesv1alpha1.GSuiteSource)
+	if !ok {
+		logger.Errorf("could not find %s source %v", r.kind.Name(), object)
+		return reconcile.Result{}, nil
+	}
+
+	// See if the source has been deleted.
+	accessor, err := meta.Accessor(source)
+	if err != nil {
+		logger.Warnw("Failed to get metadata accessor", zap.Error(err))
+		return reconcile.Result{}, err
+	}
+
+	start := time.Now()
+	if accessor.GetDeletionTimestamp() != nil {
+		err := r.finalize(ctx, source)
+		r.recordReconcile(source, start, err)
+		return reconcile.Result{}, err
+	}
+
+	reconcileErr := r.reconcile(ctx, source)
+	r.recordReconcile(source, start, reconcileErr)
+	return r.requeue(source), reconcileErr
+}
+
+// recordReconcile records the duration of a reconciliation that started at start, and the number of
+// channels of the source afterwards. The channels of the sources being finalized are no longer recorded.
+func (r *reconciler) recordReconcile(source sourcesv1alpha1.GSuiteSource, start time.Time, err error) {
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultError
+	}
+	metrics.ReconcileDuration.WithLabelValues(r.kind.Name(), result).Observe(time.Since(start).Seconds())
+
+	if source.GetDeletionTimestamp() != nil && err == nil {
+		metrics.ActiveChannels.DeleteLabelValues(r.kind.Name(), source.GetNamespace(), source.GetName())
+		return
+	}
+	channels := 0
+	for _, webhook := range source.GetGSuiteStatus().Webhooks {
+		// The polled resources do not have a push notification channel.
+		if webhook.Id != "" && webhook.ResourceId != "" && !webhook.Polled {
+			channels++
+		}
+	}
+	metrics.ActiveChannels.WithLabelValues(r.kind.Name(), source.GetNamespace(), source.GetName()).Set(float64(channels))
+}
+
+// requeue returns a Result that requeues the source when one of its webhooks needs to be renewed,
+// or when its users and their resources, e.g., the members of a group, need to be listed again.
+func (r *reconciler) requeue(source sourcesv1alpha1.GSuiteSource) reconcile.Result {
+	requeueAfter := resyncPeriod
+	for _, webhook := range source.GetGSuiteStatus().Webhooks {
+		if webhook.Expiration == nil {
+			continue
+		}
+		renewIn := time.Until(webhook.Expiration.Add(-r.kind.ChannelRenewalPeriod()))
+		if renewIn <= 0 {
+			return reconcile.Result{Requeue: true}
+		}
+		if renewIn < requeueAfter {
+			requeueAfter = renewIn
+		}
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}
+}
+
+func (r *reconciler) reconcile(ctx context.Context, source sourcesv1alpha1.GSuiteSource) error {
+	logger := logging.FromContext(ctx)
+	status := source.GetGSuiteStatus()
+	polling := source.GetGSuiteSpec().IsPolling()
+
+	status.InitializeConditions(polling)
+	if polling && !r.supportsPolling() {
+		status.MarkNoPolling("PollModeNotSupported", "%s sources cannot run in poll mode", r.kind.Name())
+		// Returning nil on purpose, as only a change of the spec can fix it.
+		return nil
+	}
+
+	if _, err := r.credentialsFrom(ctx, source); err != nil {
+		source.GetGSuiteStatus().MarkNoSecrets("GcpCredsSecretNotFound", "%s", err)
+		return err
+	}
+	tokens, err := r.reconcileTokenSecret(ctx, source)
+	if err != nil {
+		return err
+	}
+	status.MarkSecrets()
+
+	uri, err := r.sinkURIFrom(ctx, source)
+	if err != nil {
+		return err
+	}
+	status.MarkSink(uri)
+	logger.Infof("Sink URI %s", uri)
+
+	status.DeadLetterSinkURI = ""
+	if deadLetterSink := source.GetGSuiteSpec().DeadLetterSink; deadLetterSink != nil {
+		uri, err := sinks.GetSinkURI(ctx, r.client, deadLetterSink, source.GetNamespace())
+		if err != nil {
+			status.MarkNoSink("DeadLetterSinkNotFound", "%s", err)
+			return err
+		}
+		status.DeadLetterSinkURI = uri
+		logger.Infof("Dead Letter Sink URI %s", uri)
+	}
+
+	users, err := r.usersFrom(ctx, source)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Users %v", users)
+
+	polled, err := r.reconcileWatches(ctx, source, users)
+	if err != nil {
+		return err
+	}
+	if err := r.reconcileCursors(ctx, source); err != nil {
+		return err
+	}
+
+	ksvc, err := r.reconcileService(ctx, source, polled)
+	if err != nil {
+		return err
+	}
+
+	if polling {
+		return r.reconcilePolling(ctx, source, ksvc)
+	}
+
+	domain, err := r.domainFrom(ksvc, source)
+	if err != nil {
+		// Returning nil on purpose as we will wait until the next reconciliation process is triggered.
+		return nil
+	}
+	logger.Infof("Service domain %s", domain)
+	status.MarkService()
+
+	err = r.reconcileWebhooks(ctx, source, domain, tokens)
+	if err != nil {
+		return err
+	}
+	status.MarkWebHook()
+	return nil
+}
+
+// finalize stops the webhooks of the deleted source, and removes its finalizer once they are all stopped.
+// The webhooks that expired, or that Google no longer knows of, count as stopped, and so do the ones that
+// cannot be stopped as the credentials are gone, as they expire anyway. The transient errors are returned,
+// so that the source is requeued with backoff, while the rest need intervention, e.g., a fix of the permissions,
+// or the skip channel stop annotation.
+func (r *reconciler) finalize(ctx context.Context, source sourcesv1alpha1.GSuiteSource) error {
+	logger := logging.FromContext(ctx)
+	status := source.GetGSuiteStatus()
+	if source.GetAnnotations()[sourcesv1alpha1.SkipChannelStopAnnotation] == "true" {
+		logger.Warnf("Skipping the stop of the webhooks, as requested by the %s annotation", sourcesv1alpha1.SkipChannelStopAnnotation)
+		status.MarkFinalized()
+		r.removeFinalizer(source)
+		return nil
+	}
+	for i := range status.Webhooks {
+		webhook := &status.Webhooks[i]
+		if webhook.Id == "" || webhook.ResourceId == "" {
+			continue
+		}
+		if webhook.Expiration != nil && webhook.Expiration.Time.Before(time.Now()) {
+			logger.Infof("Webhook Id %s - ResourceId %s already expired", webhook.Id, webhook.ResourceId)
+		} else if err := r.stopWebhook(ctx, source, webhook, false); err != nil {
+			switch {
+			case isCredentialsNotFound(err):
+				logger.Warnf("Failed to stop Webhook Id %s - ResourceId %s, as the credentials are gone. It expires at %v",
+					webhook.Id, webhook.ResourceId, webhook.Expiration)
+			case apiErrorCode(err) == http.StatusNotFound:
+				logger.Infof("Webhook Id %s - ResourceId %s not found", webhook.Id, webhook.ResourceId)
+			case isTransient(err):
+				status.MarkFinalizing("WebHookStopFailed", "failed to stop the webhook of user %q resource %q, retrying: %s",
+					webhook.EmailAddress, webhook.Resource, err)
+				return err
+			default:
+				status.MarkNoFinalizing("WebHookStopFailed", "failed to stop the webhook of user %q resource %q: %s",
+					webhook.EmailAddress, webhook.Resource, err)
+				// Returning nil on purpose, as retrying does not help until the error is fixed,
+				// which updates the source, or the source is annotated to skip the stop.
+				logger.Errorf("Failed to stop Webhook Id %s - ResourceId %s: %v", webhook.Id, webhook.ResourceId, err)
+				return nil
+			}
+		} else {
+			logger.Infof("Successfully removed Webhook Id %s - ResourceId %s", webhook.Id, webhook.ResourceId)
+		}
+		// Forget the stopped webhooks, so that they are not stopped again if we fail to stop the rest.
+		webhook.Id = ""
+		webhook.ResourceId = ""
+	}
+	status.MarkFinalized()
+	r.removeFinalizer(source)
+	return nil
+}
+
+// apiErrorCode returns the HTTP status code of a Google API error, or zero.
+func apiErrorCode(err error) int {
+	if apiErr, ok := err.(*googleapi.Error); ok {
+		return apiErr.Code
+	}
+	return 0
+}
+
+// isTransient returns true if the error is worth retrying as is, i.e., a rate limit, a server error,
+// or an error that is not a Google API one, e.g., a network error.
+func isTransient(err error) bool {
+	code := apiErrorCode(err)
+	return code == 0 || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// supportsPolling returns true if the sources of the kind can run in poll mode.
+func (r *reconciler) supportsPolling() bool {
+	kind, ok := r.kind.(gsuite.PollingKind)
+	return ok && kind.SupportsPolling()
+}
+
+// reconcilePolling stops the webhooks created before the source switched to poll mode, as the receive adapter
+// reads the changes of the resources by itself. It does not wait for a routed domain, just for a ready service.
+func (r *reconciler) reconcilePolling(ctx context.Context, source sourcesv1alpha1.GSuiteSource, ksvc *servingv1alpha1.Service) error {
+	logger := logging.FromContext(ctx)
+	status := source.GetGSuiteStatus()
+	for i := range status.Webhooks {
+		webhook := &status.Webhooks[i]
+		if webhook.Id == "" || webhook.ResourceId == "" {
+			continue
+		}
+		// The webhook expires anyway, so we just log if we fail to stop it.
+		if err := r.stopWebhook(ctx, source, webhook, false); err != nil {
+			logger.Warnf("Failed to stop Webhook Id %s - ResourceId %s of user %q: %v", webhook.Id, webhook.ResourceId, webhook.EmailAddress, err)
+		}
+		webhook.Id = ""
+		webhook.ResourceId = ""
+		webhook.Expiration = nil
+		webhook.Token = ""
+		webhook.Domain = ""
+		webhook.WatchParams = ""
+	}
+
+	configurationsReady := ksvc.Status.GetCondition(servingv1alpha1.ServiceConditionConfigurationsReady)
+	if configurationsReady == nil || configurationsReady.Status != corev1.ConditionTrue {
+		status.MarkNoService("ServiceNotReady", "service %q is not ready", ksvc.Name)
+		// Returning nil on purpose as we will wait until the next reconciliation process is triggered.
+		return nil
+	}
+	status.MarkService()
+	status.MarkPolling()
+	return nil
+}
+
+func (r *reconciler) domainFrom(ksvc *servingv1alpha1.Service, source sourcesv1alpha1.GSuiteSource) (string, error) {
+	routeCondition := ksvc.Status.GetCondition(servingv1alpha1.ServiceConditionRoutesReady)
+	receiveAdapterDomain := ksvc.Status.Domain
+	if routeCondition != nil && routeCondition.Status == corev1.ConditionTrue && receiveAdapterDomain != "" {
+		return receiveAdapterDomain, nil
+	}
+	err := fmt.Errorf("domain not found for svc %q", ksvc.Name)
+	source.GetGSuiteStatus().MarkNoService("ServiceDomainNotFound", "%s", err)
+	return "", err
+}
+
+func (r *reconciler) reconcileService(ctx context.Context, source sourcesv1alpha1.GSuiteSource, polled bool) (*servingv1alpha1.Service, error) {
+	loggingConfig, err := r.loggingConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.getService(ctx, source)
+
+	// If the resource doesn't exist, we'll create it.
+	if apierrors.IsNotFound(err) {
+		ksvc, err := r.newService(source, polled, loggingConfig)
+		if err != nil {
+			return nil, err
+		}
+		err = r.client.Create(ctx, ksvc)
+		if err != nil {
+			source.GetGSuiteStatus().MarkNoService("ServiceCreateFailed", "%s", err)
+			return nil, err
+		}
+		return ksvc, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// The receive adapter reads what to watch, and where to send the events, from its environment,
+	// so we update it when that changes, or when the controller runs with a new receive adapter image.
+	desired, err := r.newService(source, polled, loggingConfig)
+	if err != nil {
+		return nil, err
+	}
+	if serviceChanged(current, desired) {
+		logging.FromContext(ctx).Infof("Updating service %q", current.Name)
+		current.Spec = desired.Spec
+		err = r.client.Update(ctx, current)
+		if err != nil {
+			source.GetGSuiteStatus().MarkNoService("ServiceUpdateFailed", "%s", err)
+			return nil, err
+		}
+	}
+	return current, nil
+}
+
+// loggingConfig returns the data of the config-logging ConfigMap, which the receive adapters log with.
+// If the ConfigMap does not exist, the receive adapters log with the default config.
+func (r *reconciler) loggingConfig(ctx context.Context) (map[string]string, error) {
+	cm, err := r.configMaps.ConfigMaps(r.systemNamespace).Get(resources.LoggingConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		logging.FromContext(ctx).Warnf("ConfigMap %q not found, using the default logging config", resources.LoggingConfigMapName)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return cm.Data, nil
+}
+
+// minScale returns the minimum number of replicas of the receive adapter, if set.
+func minScale(ksvc *servingv1alpha1.Service) string {
+	if ksvc.Spec.RunLatest == nil {
+		return ""
+	}
+	return ksvc.Spec.RunLatest.Configuration.RevisionTemplate.Annotations[resources.MinScaleAnnotation]
+}
+
+// serviceChanged returns true if the receive adapter differs from the desired one in its image, environment,
+// service account, secrets or minimum number of replicas. The rest of the spec is defaulted by Knative, so it
+// is not compared.
+func serviceChanged(current, desired *servingv1alpha1.Service) bool {
+	if current.Spec.RunLatest == nil {
+		return true
+	}
+	currentSpec := current.Spec.RunLatest.Configuration.RevisionTemplate.Spec
+	desiredSpec := desired.Spec.RunLatest.Configuration.RevisionTemplate.Spec
+	return currentSpec.Container.Image != desiredSpec.Container.Image ||
+		!equality.Semantic.DeepEqual(currentSpec.Container.Env, desiredSpec.Container.Env) ||
+		currentSpec.ServiceAccountName != desiredSpec.ServiceAccountName ||
+		!equality.Semantic.DeepEqual(secretNames(currentSpec.Volumes), secretNames(desiredSpec.Volumes)) ||
+		minScale(current) != minScale(desired)
+}
+
+// secretNames returns the names of the secrets mounted by the given volumes.
+func secretNames(volumes []corev1.Volume) []string {
+	var names []string
+	for _, volume := range volumes {
+		if volume.Secret != nil {
+			names = append(names, volume.Secret.SecretName)
+		}
+	}
+	return names
+}
+
+// envValue returns the value of the given environment variable of the receive adapter.
+func envValue(ksvc *servingv1alpha1.Service, name string) string {
+	if ksvc.Spec.RunLatest == nil {
+		return ""
+	}
+	for _, env := range ksvc.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+// usersFrom returns the email addresses of the users the source watches.
+// The sources that cannot watch many users just watch the one of their spec, if any.
+func (r *reconciler) usersFrom(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]string, error) {
+	spec := source.GetGSuiteSpec()
+	multi, ok := source.(sourcesv1alpha1.GSuiteMultiUserSource)
+	if !ok {
+		return []string{spec.EmailAddress}, nil
+	}
+
+	usersSpec := multi.GetGSuiteUsersSpec()
+	users := sets.NewString(usersSpec.EmailAddresses...)
+	if spec.EmailAddress != "" {
+		users.Insert(spec.EmailAddress)
+	}
+	if usersSpec.Group != "" {
+		credentials, err := r.credentialsFrom(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		members, err := gsuite.GroupMembers(ctx, credentials, usersSpec.AdminEmailAddress, usersSpec.Group)
+		if err != nil {
+			metrics.RecordAPIError(r.kind.Name(), err)
+			markNoWatch(source, "GroupMembersFailed", "%s", err)
+			return nil, err
+		}
+		users.Insert(members...)
+	}
+	if users.Len() == 0 {
+		err := fmt.Errorf("no users to watch")
+		markNoWatch(source, "UsersNotFound", "%s", err)
+		return nil, err
+	}
+	return users.List(), nil
+}
+
+// markNoWatch sets the condition that the source cannot watch the changes of its resources,
+// or poll them in poll mode.
+func markNoWatch(source sourcesv1alpha1.GSuiteSource, reason, messageFormat string, messageA ...interface{}) {
+	if source.GetGSuiteSpec().IsPolling() {
+		source.GetGSuiteStatus().MarkNoPolling(reason, messageFormat, messageA...)
+		return
+	}
+	source.GetGSuiteStatus().MarkNoWebHook(reason, messageFormat, messageA...)
+}
+
+// reconcileWatches adds a webhook entry for each new resource of each user, with the position from where
+// the receive adapter starts reading its changes, e.g., the Drive Changes start page token. It also stops
+// the webhooks of the users and resources that are no longer watched. It returns whether the receive adapter
+// polls any of the resources, e.g., the members of a group, or all of them in poll mode.
+func (r *reconciler) reconcileWatches(ctx context.Context, source sourcesv1alpha1.GSuiteSource, users []string) (bool, error) {
+	logger := logging.FromContext(ctx)
+	status := source.GetGSuiteStatus()
+
+	var watches []sourcesv1alpha1.Webhook
+	var errs []string
+	polled := source.GetGSuiteSpec().IsPolling()
+	for _, user := range users {
+		gsClient, err := r.newClient(ctx, source, user)
+		if err != nil {
+			markNoWatch(source, "CursorFailed", "%s", err)
+			return false, err
+		}
+		poller, isPoller := gsClient.(gsuite.Poller)
+		resources, err := gsClient.Resources(ctx, source)
+		if err != nil {
+			metrics.RecordAPIError(r.kind.Name(), err)
+			// Keep watching the resources we already know of.
+			errs = append(errs, fmt.Sprintf("failed to list the resources of user %q: %v", user, err))
+			for _, webhook := range status.Webhooks {
+				if webhook.EmailAddress == user {
+					watches = append(watches, webhook)
+					polled = polled || (isPoller && poller.PollInterval(webhook.Resource) > 0)
+				}
+			}
+			continue
+		}
+		for _, resource := range resources {
+			resourcePolled := isPoller && poller.PollInterval(resource) > 0
+			polled = polled || resourcePolled
+			if webhook := status.GetWebhook(user, resource); webhook != nil {
+				webhook.Polled = resourcePolled
+				watches = append(watches, *webhook)
+				continue
+			}
+			cursor, err := gsClient.Cursor(ctx, source, resource)
+			if err != nil {
+				metrics.RecordAPIError(r.kind.Name(), err)
+				// We retry on the next reconciliation.
+				errs = append(errs, fmt.Sprintf("failed to get the cursor of user %q resource %q: %v", user, resource, err))
+				continue
+			}
+			logger.Infof("User %q Resource %q Cursor %s", user, resource, cursor)
+			watches = append(watches, sourcesv1alpha1.Webhook{
+				EmailAddress: user,
+				Resource:     resource,
+				Cursor:       cursor,
+				Polled:       resourcePolled,
+			})
+		}
+	}
+
+	watched := make(map[[2]string]bool, len(watches))
+	for _, watch := range watches {
+		watched[[2]string{watch.EmailAddress, watch.Resource}] = true
+	}
+	for i := range status.Webhooks {
+		webhook := &status.Webhooks[i]
+		if watched[[2]string{webhook.EmailAddress, webhook.Resource}] || webhook.Id == "" || webhook.ResourceId == "" {
+			continue
+		}
+		// The webhook expires anyway, so we just log if we fail to stop it.
+		if err := r.stopWebhook(ctx, source, webhook, false); err != nil {
+			logger.Warnf("Failed to stop Webhook Id %s - ResourceId %s of user %q: %v", webhook.Id, webhook.ResourceId, webhook.EmailAddress, err)
+		}
+	}
+	status.Webhooks = watches
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("%s", strings.Join(errs, "; "))
+		markNoWatch(source, "CursorFailed", "%s", err)
+		return polled, err
+	}
+	return polled, nil
+}
+
+// reconcileCursors adds the cursors of the new resources to the cursors ConfigMap of the source, which the receive
+// adapter reads them from, and removes the ones of the resources no longer watched. The cursors already in the
+// ConfigMap are the ones the receive adapter saved as it read the changes, so they are kept.
+func (r *reconciler) reconcileCursors(ctx context.Context, source sourcesv1alpha1.GSuiteSource) error {
+	if err := r.reconcileAdapterAccess(ctx, source); err != nil {
+		return err
+	}
+	cursors := make(map[string]string)
+	for _, webhook := range source.GetGSuiteStatus().Webhooks {
+		cursors[gsuite.CursorKey(webhook.EmailAddress, webhook.Resource)] = webhook.Cursor
+	}
+
+	configMaps := r.configMaps.ConfigMaps(source.GetNamespace())
+	name := resources.CursorsConfigMapName(r.kind.Name(), source)
+	cm, err := configMaps.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = resources.MakeCursorsConfigMap(r.kind.Name(), source, cursors)
+		if err := controllerutil.SetControllerReference(source, cm, r.scheme); err != nil {
+			return err
+		}
+		if _, err := configMaps.Create(cm); err != nil {
+			markNoWatch(source, "CursorsConfigMapCreateFailed", "%s", err)
+			return err
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(cm, source) {
+		err = fmt.Errorf("configmap %q is not owned by %s source %q", cm.Name, r.kind.Name(), source.GetName())
+		markNoWatch(source, "CursorsConfigMapNotOwned", "%s", err)
+		return err
+	}
+
+	// The receive adapter saves the cursors concurrently, so we only patch the keys we add or remove.
+	changes := make(map[string]*string)
+	for key, cursor := range cursors {
+		if _, ok := cm.Data[key]; !ok {
+			cursor := cursor
+			changes[key] = &cursor
+		}
+	}
+	for key := range cm.Data {
+		if _, ok := cursors[key]; !ok {
+			changes[key] = nil
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"data": changes})
+	if err != nil {
+		return err
+	}
+	if _, err := configMaps.Patch(name, types.StrategicMergePatchType, patch); err != nil {
+		markNoWatch(source, "CursorsConfigMapUpdateFailed", "%s", err)
+		return err
+	}
+	return nil
+}
+
+// reconcileAdapterAccess creates the ServiceAccount the receive adapter runs as, and the Role and RoleBinding
+// that allow it to save its cursors, if they do not exist yet.
+func (r *reconciler) reconcileAdapterAccess(ctx context.Context, source sourcesv1alpha1.GSuiteSource) error {
+	objects := []runtime.Object{
+		resources.MakeAdapterServiceAccount(r.kind.Name(), source),
+		resources.MakeAdapterRole(r.kind.Name(), source),
+		resources.MakeAdapterRoleBinding(r.kind.Name(), source),
+	}
+	for _, object := range objects {
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			return err
+		}
+		err = r.client.Get(ctx, client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}, object.DeepCopyObject())
+		if err == nil {
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err := controllerutil.SetControllerReference(source, accessor, r.scheme); err != nil {
+			return err
+		}
+		if err := r.client.Create(ctx, object); err != nil {
+			source.GetGSuiteStatus().MarkNoService("AdapterAccessCreateFailed", "%s", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileWebhooks creates the webhooks of the new users, renews the ones about to expire, and creates
+// again the ones whose domain or watch params changed. All the webhooks created use the latest token,
+// which is then rotated, and their channels are saved in the channel tokens secret.
+func (r *reconciler) reconcileWebhooks(ctx context.Context, source sourcesv1alpha1.GSuiteSource, domain string, tokens *corev1.Secret) (err error) {
+	logger := logging.FromContext(ctx)
+	status := source.GetGSuiteStatus()
+	tokenKey := latestTokenKey(tokens)
+	params := r.watchParams(source)
+
+	created := false
+	defer func() {
+		// The receive adapter rejects the notifications of the channels missing from the secret,
+		// so we retry on the next reconciliation if we fail to update it.
+		if updateErr := r.updateTokenSecret(ctx, source, tokens, created); updateErr != nil {
+			logger.Warnf("Failed to update the channel tokens secret: %v", updateErr)
+			if err == nil {
+				err = updateErr
+			}
+		}
+	}()
+
+	for i := range status.Webhooks {
+		webhook := &status.Webhooks[i]
+		// If webhook doesn't exist, then create it.
+		if webhook.Id == "" || webhook.ResourceId == "" {
+			r.addFinalizer(source)
+
+			channel, err := r.createWebhook(ctx, source, webhook, domain, tokens.Data[tokenKey])
+			if err != nil {
+				status.MarkNoWebHook("WebHookCreateFailed", "failed to create the webhook of user %q resource %q: %s", webhook.EmailAddress, webhook.Resource, err)
+				return err
+			}
+			r.setWebhook(webhook, channel, tokenKey, domain, params)
+			created = true
+			metrics.ChannelCreations.WithLabelValues(r.kind.Name()).Inc()
+			logger.Infof("WebHook Id %s - ResourceId %s of user %q resource %q", channel.Id, channel.ResourceId, webhook.EmailAddress, webhook.Resource)
+			continue
+		}
+
+		changed := webhook.Domain != domain || webhook.WatchParams != params
+		if !changed && !r.needsRenewal(webhook) {
+			continue
+		}
+		// Create the new webhook before stopping the current one, so that we do not miss notifications.
+		old := *webhook
+		channel, err := r.createWebhook(ctx, source, webhook, domain, tokens.Data[tokenKey])
+		if err != nil {
+			switch {
+			case changed:
+				status.MarkNoWebHook("WebHookUpdateFailed", "failed to update the webhook of user %q resource %q: %s", webhook.EmailAddress, webhook.Resource, err)
+			case webhook.Expiration.Time.Before(time.Now()):
+				status.MarkNoWebHookRenewal("WebHookRenewFailed", "failed to renew the expired webhook of user %q resource %q: %s", webhook.EmailAddress, webhook.Resource, err)
+			default:
+				status.MarkNoWebHookRenewal("WebHookRenewFailed", "failed to renew the webhook of user %q resource %q, which expires at %v: %s",
+					webhook.EmailAddress, webhook.Resource, webhook.Expiration.Time, err)
+			}
+			return err
+		}
+		r.setWebhook(webhook, channel, tokenKey, domain, params)
+		created = true
+		if changed {
+			metrics.ChannelCreations.WithLabelValues(r.kind.Name()).Inc()
+			logger.Infof("Updated Webhook Id %s - ResourceId %s of user %q resource %q to domain %s", channel.Id, channel.ResourceId, webhook.EmailAddress, webhook.Resource, domain)
+		} else {
+			metrics.ChannelRenewals.WithLabelValues(r.kind.Name()).Inc()
+			logger.Infof("Renewed Webhook Id %s - ResourceId %s of user %q resource %q", channel.Id, channel.ResourceId, webhook.EmailAddress, webhook.Resource)
+		}
+
+		// Some kinds, e.g., Gmail, update the webhook in place, so there is nothing to stop.
+		if old.Id == channel.Id {
+			continue
+		}
+		// The old webhook expires anyway, so we just log if we fail to stop it.
+		if err := r.stopWebhook(ctx, source, &old, true); err != nil {
+			logger.Warnf("Failed to stop replaced Webhook Id %s - ResourceId %s: %v", old.Id, old.ResourceId, err)
+		}
+	}
+	return nil
+}
+
+// watchParams returns the fields of the source the webhooks are created with, besides the user and the resource.
+func (r *reconciler) watchParams(source sourcesv1alpha1.GSuiteSource) string {
+	if kind, ok := r.kind.(gsuite.WatchParamsKind); ok {
+		return kind.WatchParams(source)
+	}
+	return ""
+}
+
+// needsRenewal returns true if the webhook expires within the renewal period.
+func (r *reconciler) needsRenewal(webhook *sourcesv1alpha1.Webhook) bool {
+	return webhook.Expiration != nil && time.Until(webhook.Expiration.Time) < r.kind.ChannelRenewalPeriod()
+}
+
+func (r *reconciler) setWebhook(webhook *sourcesv1alpha1.Webhook, channel *gsuite.Channel, tokenKey, domain, params string) {
+	webhook.Id = channel.Id
+	webhook.ResourceId = channel.ResourceId
+	webhook.Token = tokenKey
+	webhook.Domain = domain
+	webhook.WatchParams = params
+	webhook.Expiration = nil
+	if !channel.Expiration.IsZero() {
+		expiration := metav1.NewTime(channel.Expiration)
+		webhook.Expiration = &expiration
+	}
+}
+
+func (r *reconciler) createWebhook(ctx context.Context, source sourcesv1alpha1.GSuiteSource, webhook *sourcesv1alpha1.Webhook, domain string, token []byte) (*gsuite.Channel, error) {
+	gsClient, err := r.newClient(ctx, source, webhook.EmailAddress)
+	if err != nil {
+		return nil, err
+	}
+	channel := &gsuite.Channel{
+		Id:    string(uuid.NewUUID()),
+		Token: string(token),
+		// The receive adapter tells the users and resources apart by the path of the notifications.
+		Address:    fmt.Sprintf("https://%s/%s/%s", domain, url.PathEscape(webhook.EmailAddress), url.PathEscape(webhook.Resource)),
+		Expiration: time.Now().Add(r.kind.ChannelExpiration()),
+	}
+	channel, err = gsClient.Watch(ctx, source, webhook.Resource, webhook.Cursor, channel)
+	if err != nil {
+		metrics.RecordAPIError(r.kind.Name(), err)
+		return nil, err
+	}
+	return channel, nil
+}
+
+// reconcileTokenSecret creates the secret holding the channel tokens of the source, if it does not exist yet.
+func (r *reconciler) reconcileTokenSecret(ctx context.Context, source sourcesv1alpha1.GSuiteSource) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: source.GetNamespace(), Name: resources.TokenSecretName(r.kind.Name(), source)}, secret)
+	if err == nil {
+		if !metav1.IsControlledBy(secret, source) {
+			err = fmt.Errorf("secret %q is not owned by %s source %q", secret.Name, r.kind.Name(), source.GetName())
+			source.GetGSuiteStatus().MarkNoSecrets("ChannelTokenSecretNotOwned", "%s", err)
+			return nil, err
+		}
+		if latestTokenKey(secret) == "" {
+			err = fmt.Errorf("secret %q does not have any channel token", secret.Name)
+			source.GetGSuiteStatus().MarkNoSecrets("ChannelTokenNotFound", "%s", err)
+			return nil, err
+		}
+		return secret, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	secret = resources.MakeTokenSecret(r.kind.Name(), source, token)
+	if err := controllerutil.SetControllerReference(source, secret, r.scheme); err != nil {
+		return nil, err
+	}
+	if err := r.client.Create(ctx, secret); err != nil {
+		source.GetGSuiteStatus().MarkNoSecrets("ChannelTokenSecretCreateFailed", "%s", err)
+		return nil, err
+	}
+	return secret, nil
+}
+
+// updateTokenSecret saves the channels of the webhooks in the secret, if they changed. If rotate is true, it also
+// adds a new latest token to the secret, and removes the tokens no longer used by any webhook.
+func (r *reconciler) updateTokenSecret(ctx context.Context, source sourcesv1alpha1.GSuiteSource, tokens *corev1.Secret, rotate bool) error {
+	channels, err := resources.Channels(source)
+	if err != nil {
+		return err
+	}
+	if !rotate && bytes.Equal(tokens.Data[resources.ChannelsKey], channels) {
+		return nil
+	}
+	if rotate {
+		token, err := newToken()
+		if err != nil {
+			return err
+		}
+		used := sets.NewString()
+		for _, webhook := range source.GetGSuiteStatus().Webhooks {
+			used.Insert(webhook.Token)
+		}
+		latest := resources.TokenKey(resources.TokenIndex(latestTokenKey(tokens)) + 1)
+		for key := range tokens.Data {
+			if resources.TokenIndex(key) >= 0 && !used.Has(key) {
+				delete(tokens.Data, key)
+			}
+		}
+		tokens.Data[latest] = []byte(token)
+	}
+	tokens.Data[resources.ChannelsKey] = channels
+	return r.client.Update(ctx, tokens)
+}
+
+// latestTokenKey returns the key of the latest token of the secret, or empty if there is none.
+func latestTokenKey(tokens *corev1.Secret) string {
+	latest := ""
+	for key := range tokens.Data {
+		if n := resources.TokenIndex(key); n >= 0 && (latest == "" || n > resources.TokenIndex(latest)) {
+			latest = key
+		}
+	}
+	return latest
+}
+
+// newToken returns a random channel token.
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// stopWebhook stops the webhook. The webhooks replaced by new ones are stopped with the
+// ReplacedStopper of the client, if any.
+func (r *reconciler) stopWebhook(ctx context.Context, source sourcesv1alpha1.GSuiteSource, webhook *sourcesv1alpha1.Webhook, replaced bool) error {
+	gsClient, err := r.newClient(ctx, source, webhook.EmailAddress)
+	if err != nil {
+		return err
+	}
+	channel := &gsuite.Channel{
+		Id:         webhook.Id,
+		ResourceId: webhook.ResourceId,
+	}
+	stop := gsClient.Stop
+	if stopper, ok := gsClient.(gsuite.ReplacedStopper); ok && replaced {
+		stop = stopper.StopReplaced
+	}
+	if err := stop(ctx, channel); err != nil {
+		metrics.RecordAPIError(r.kind.Name(), err)
+		return err
+	}
+	metrics.ChannelStops.WithLabelValues(r.kind.Name()).Inc()
+	return nil
+}
+
+// newClient returns the G Suite client impersonating the given user, if any.
+func (r *reconciler) newClient(ctx context.Context, source sourcesv1alpha1.GSuiteSource, email string) (gsuite.Client, error) {
+	credentials, err := r.credentialsFrom(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return r.kind.NewClient(ctx, credentials, email)
+}
+
+// credentialsFrom returns the service account key the G Suite clients of the source authenticate with,
+// from its gcpCredsSecret, which lives in the namespace of the source.
+func (r *reconciler) credentialsFrom(ctx context.Context, source sourcesv1alpha1.GSuiteSource) ([]byte, error) {
+	selector := source.GetGSuiteSpec().GcpCredsSecret
+	secret := &corev1.Secret{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: source.GetNamespace(), Name: selector.Name}, secret)
+	if err != nil {
+		return nil, err
+	}
+	credentials, ok := secret.Data[selector.Key]
+	if !ok {
+		return nil, &credentialsKeyNotFoundError{secret: selector.Name, key: selector.Key}
+	}
+	return credentials, nil
+}
+
+// credentialsKeyNotFoundError is returned when the gcpCredsSecret of a source does not have its key.
+type credentialsKeyNotFoundError struct {
+	secret, key string
+}
+
+func (e *credentialsKeyNotFoundError) Error() string {
+	return fmt.Sprintf("key %q not found in secret %q", e.key, e.secret)
+}
+
+// isCredentialsNotFound returns true if the gcpCredsSecret of a source, or its key, does not exist.
+func isCredentialsNotFound(err error) bool {
+	_, ok := err.(*credentialsKeyNotFoundError)
+	return ok || apierrors.IsNotFound(err)
+}
+
+func (r *reconciler) sinkURIFrom(ctx context.Context, source sourcesv1alpha1.GSuiteSource) (string, error) {
+	uri, err := sinks.GetSinkURI(ctx, r.client, source.GetGSuiteSpec().Sink, source.GetNamespace())
+	if err != nil {
+		source.GetGSuiteStatus().MarkNoSink("SinkNotFound", "%s", err)
+		return "", err
+	}
+	return uri, err
+}
+
+func (r *reconciler) getService(ctx context.Context, source sourcesv1alpha1.GSuiteSource) (*servingv1alpha1.Service, error) {
+	list := &servingv1alpha1.ServiceList{}
+	err := r.client.List(ctx, &client.ListOptions{
+		Namespace:     source.GetNamespace(),
+		LabelSelector: labels.Everything(),
+		Raw: &metav1.ListOptions{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: servingv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "Service",
+			},
+		},
+	},
+		list)
+	if err != nil {
+		return nil, err
+	}
+	for _, ksvc := range list.Items {
+		if metav1.IsControlledBy(&ksvc, source) {
+			return &ksvc, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(servingv1alpha1.Resource("services"), "")
+}
+
+func (r *reconciler) newService(source sourcesv1alpha1.GSuiteSource, polled bool, loggingConfig map[string]string) (*servingv1alpha1.Service, error) {
+	ksvc, err := resources.MakeService(r.kind.Name(), source, r.receiveAdapterImage, polled, loggingConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(source, ksvc, r.scheme); err != nil {
+		return nil, err
+	}
+	return ksvc, nil
+}
+
+func (r *reconciler) addFinalizer(s sourcesv1alpha1.GSuiteSource) {
+	finalizers := sets.NewString(s.GetFinalizers()...)
+	finalizers.Insert(r.finalizerName)
+	s.SetFinalizers(finalizers.List())
+}
+
+func (r *reconciler) removeFinalizer(s sourcesv1alpha1.GSuiteSource) {
+	finalizers := sets.NewString(s.GetFinalizers()...)
+	finalizers.Delete(r.finalizerName)
+	s.SetFinalizers(finalizers.List())
+}
+
+func (r *reconciler) InjectClient(c client.Client) error {
+	r.client = c
+	return nil
+}
@@ -0,0 +1,991 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/apis"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	"github.com/nachocano/gsuite-source/pkg/gsuite/calendar"
+	"github.com/nachocano/gsuite-source/pkg/gsuite/drive"
+	"github.com/nachocano/gsuite-source/pkg/gsuite/gmail"
+	"github.com/nachocano/gsuite-source/pkg/reconciler/resources"
+	gstesting "github.com/nachocano/gsuite-source/pkg/testing"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakecorev1 "k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	clienttesting "k8s.io/client-go/testing"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	testNamespace    = "default"
+	testSourceName   = "test-source"
+	testSourceUID    = "test-source-uid"
+	testEmail        = "user@example.com"
+	testSecretName   = "gcp-creds"
+	testSecretKey    = "key.json"
+	testSinkName     = "test-sink"
+	testSinkHost     = "test-sink.default.svc.cluster.local"
+	testDomain       = "test-source-adapter.default.example.com"
+	testSystemNS     = "knative-sources"
+	testFinalizer    = "test-source-controller"
+	testChannelId    = "channel-1"
+	testResourceId   = "resource-1"
+	testAdapterImage = "adapter-image"
+)
+
+func init() {
+	// The fake client decodes the objects with the client-go scheme, so our types must be registered in it.
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+}
+
+// testKind is a G Suite kind whose reconciler is tested, with the Google API methods of its channels.
+type testKind struct {
+	name      string
+	kind      func(api *gstesting.GoogleAPI) gsuite.Kind
+	newSource func() sourcesv1alpha1.GSuiteSource
+	// resource is the resource of the test user watched by default.
+	resource string
+	watch    string
+	stop     string
+}
+
+var testKinds = []testKind{{
+	name: "drive",
+	kind: func(api *gstesting.GoogleAPI) gsuite.Kind {
+		return drive.Kind{ClientOptions: api.DriveOptions()}
+	},
+	newSource: func() sourcesv1alpha1.GSuiteSource {
+		return &sourcesv1alpha1.DriveSource{
+			TypeMeta: metav1.TypeMeta{APIVersion: sourcesv1alpha1.SchemeGroupVersion.String(), Kind: "DriveSource"},
+		}
+	},
+	watch: gstesting.DriveChangesWatch,
+	// Drive watches a single resource per user.
+	resource: "",
+	stop:     gstesting.DriveChannelsStop,
+}, {
+	name: "calendar",
+	kind: func(api *gstesting.GoogleAPI) gsuite.Kind {
+		return calendar.Kind{ClientOptions: api.CalendarOptions()}
+	},
+	newSource: func() sourcesv1alpha1.GSuiteSource {
+		return &sourcesv1alpha1.CalendarSource{
+			TypeMeta: metav1.TypeMeta{APIVersion: sourcesv1alpha1.SchemeGroupVersion.String(), Kind: "CalendarSource"},
+		}
+	},
+	watch: gstesting.CalendarEventsWatch,
+	// The primary calendar is identified by the email address of the user.
+	resource: testEmail,
+	stop:     gstesting.CalendarChannelsStop,
+}}
+
+// wantCondition is the expected status and reason of a condition of the source.
+type wantCondition struct {
+	status corev1.ConditionStatus
+	reason string
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name string
+		// objects are the objects in the cluster, besides the source.
+		objects func(source sourcesv1alpha1.GSuiteSource) []runtime.Object
+		// mutate modifies the source before it is reconciled, e.g., to delete it.
+		mutate func(kt testKind, source sourcesv1alpha1.GSuiteSource)
+		// responses are scripted for the watch and stop methods of the kind.
+		watchResponses []gstesting.Response
+		stopResponses  []gstesting.Response
+		wantErr        bool
+		wantConditions map[duckv1alpha1.ConditionType]wantCondition
+		wantChannels   int
+		// wantStops is the number of webhooks the reconciler tries to stop.
+		wantStops     int
+		wantFinalizer bool
+		// wantService is whether a receive adapter service exists after the reconciliation.
+		wantService bool
+	}{{
+		name: "missing secret",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{sink()}
+		},
+		wantErr: true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "GcpCredsSecretNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionSecretsProvided: {corev1.ConditionFalse, "GcpCredsSecretNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionSinkProvided:    {corev1.ConditionUnknown, ""},
+		},
+	}, {
+		name: "missing secret key",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			secret := gcpSecret()
+			secret.Data = map[string][]byte{"other.json": []byte(gstesting.Credentials)}
+			return []runtime.Object{secret, sink()}
+		},
+		wantErr: true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "GcpCredsSecretNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionSecretsProvided: {corev1.ConditionFalse, "GcpCredsSecretNotFound"},
+		},
+	}, {
+		name: "missing sink",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret()}
+		},
+		wantErr: true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "SinkNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionSecretsProvided: {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionSinkProvided:    {corev1.ConditionFalse, "SinkNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionServiceProvided: {corev1.ConditionUnknown, ""},
+		},
+	}, {
+		name: "service without domain",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink()}
+		},
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "ServiceDomainNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionSecretsProvided: {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionSinkProvided:    {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionServiceProvided: {corev1.ConditionFalse, "ServiceDomainNotFound"},
+			sourcesv1alpha1.GSuiteSourceConditionWebHookProvided: {corev1.ConditionUnknown, ""},
+		},
+		wantService: true,
+	}, {
+		name: "webhook create failure",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		watchResponses: []gstesting.Response{gstesting.ErrorResponse(http.StatusInternalServerError, "backend error")},
+		wantErr:        true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "WebHookCreateFailed"},
+			sourcesv1alpha1.GSuiteSourceConditionServiceProvided: {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionWebHookProvided: {corev1.ConditionFalse, "WebHookCreateFailed"},
+		},
+		// The finalizer is added before the webhook is created, so that it is never leaked.
+		wantFinalizer: true,
+		wantService:   true,
+	}, {
+		name: "ready",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionSecretsProvided: {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionSinkProvided:    {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionServiceProvided: {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionWebHookProvided: {corev1.ConditionTrue, ""},
+		},
+		wantChannels:  1,
+		wantFinalizer: true,
+		wantService:   true,
+	}, {
+		name: "webhook renewal failure",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate:         withReadyWebhook(time.Minute),
+		watchResponses: []gstesting.Response{gstesting.ErrorResponse(http.StatusInternalServerError, "backend error")},
+		wantErr:        true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "WebHookRenewFailed"},
+			sourcesv1alpha1.GSuiteSourceConditionWebHookProvided: {corev1.ConditionFalse, "WebHookRenewFailed"},
+		},
+		wantFinalizer: true,
+		wantService:   true,
+	}, {
+		name: "expired webhook renewal failure",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate:         withReadyWebhook(-time.Minute),
+		watchResponses: []gstesting.Response{gstesting.ErrorResponse(http.StatusInternalServerError, "backend error")},
+		wantErr:        true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionFalse, "WebHookRenewFailed"},
+			sourcesv1alpha1.GSuiteSourceConditionWebHookProvided: {corev1.ConditionFalse, "WebHookRenewFailed"},
+		},
+		wantFinalizer: true,
+		wantService:   true,
+	}, {
+		name: "webhook renewal",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate: withReadyWebhook(time.Minute),
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionReady:           {corev1.ConditionTrue, ""},
+			sourcesv1alpha1.GSuiteSourceConditionWebHookProvided: {corev1.ConditionTrue, ""},
+		},
+		wantChannels: 1,
+		// The renewed webhook is stopped, though the fake API does not know of it.
+		wantStops:     1,
+		wantFinalizer: true,
+		wantService:   true,
+	}, {
+		name: "finalize",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate:        anyKind(deleteWithWebhook(time.Hour)),
+		stopResponses: []gstesting.Response{{Code: http.StatusNoContent}},
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionTrue, ""},
+		},
+		wantStops:   1,
+		wantService: true,
+	}, {
+		name: "finalize channel not found",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate: anyKind(deleteWithWebhook(time.Hour)),
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionTrue, ""},
+		},
+		wantStops:   1,
+		wantService: true,
+	}, {
+		name: "finalize expired channel",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate: anyKind(deleteWithWebhook(-time.Hour)),
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionTrue, ""},
+		},
+		wantService: true,
+	}, {
+		name: "finalize without credentials",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{sink(), readyService(t, source)}
+		},
+		mutate: anyKind(deleteWithWebhook(time.Hour)),
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionTrue, ""},
+		},
+		wantService: true,
+	}, {
+		name: "finalize skipping the channel stop",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate: func(kt testKind, source sourcesv1alpha1.GSuiteSource) {
+			deleteWithWebhook(time.Hour)(source)
+			source.SetAnnotations(map[string]string{sourcesv1alpha1.SkipChannelStopAnnotation: "true"})
+		},
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionTrue, ""},
+		},
+		wantService: true,
+	}, {
+		name: "finalize transient stop failure",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate:        anyKind(deleteWithWebhook(time.Hour)),
+		stopResponses: []gstesting.Response{gstesting.ErrorResponse(http.StatusInternalServerError, "backend error")},
+		wantErr:       true,
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionUnknown, "WebHookStopFailed"},
+		},
+		wantStops:     1,
+		wantFinalizer: true,
+		wantService:   true,
+	}, {
+		name: "finalize permanent stop failure",
+		objects: func(source sourcesv1alpha1.GSuiteSource) []runtime.Object {
+			return []runtime.Object{gcpSecret(), sink(), readyService(t, source)}
+		},
+		mutate:        anyKind(deleteWithWebhook(time.Hour)),
+		stopResponses: []gstesting.Response{gstesting.ErrorResponse(http.StatusForbidden, "insufficient permissions")},
+		wantConditions: map[duckv1alpha1.ConditionType]wantCondition{
+			sourcesv1alpha1.GSuiteSourceConditionFinalizing: {corev1.ConditionFalse, "WebHookStopFailed"},
+		},
+		wantStops:     1,
+		wantFinalizer: true,
+		wantService:   true,
+	}}
+
+	for _, kt := range testKinds {
+		for _, tt := range tests {
+			t.Run(kt.name+"/"+tt.name, func(t *testing.T) {
+				api := gstesting.NewGoogleAPI()
+				defer api.Close()
+				api.Script(kt.watch, tt.watchResponses...)
+				api.Script(kt.stop, tt.stopResponses...)
+
+				source := newSource(kt)
+				if tt.mutate != nil {
+					tt.mutate(kt, source)
+				}
+				r := newTestReconciler(t, kt.kind(api), tt.objects(source)...)
+
+				_, err := r.Reconcile(context.Background(), source)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Reconcile() = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				status := source.GetGSuiteStatus()
+				for condType, want := range tt.wantConditions {
+					got := status.GetCondition(condType)
+					if got == nil {
+						t.Errorf("condition %s not found", condType)
+						continue
+					}
+					if got.Status != want.status || got.Reason != want.reason {
+						t.Errorf("condition %s = %s %q, want %s %q", condType, got.Status, got.Reason, want.status, want.reason)
+					}
+				}
+				if got := len(api.Channels()); got != tt.wantChannels {
+					t.Errorf("got %d channels, want %d", got, tt.wantChannels)
+				}
+				if got := len(api.Requests(kt.stop)); got != tt.wantStops {
+					t.Errorf("got %d stop requests, want %d", got, tt.wantStops)
+				}
+				if got := hasFinalizer(source, testFinalizer); got != tt.wantFinalizer {
+					t.Errorf("finalizer = %v, want %v", got, tt.wantFinalizer)
+				}
+				if _, err := r.getService(context.Background(), source); (err == nil) != tt.wantService {
+					t.Errorf("getService() = %v, want service %v", err, tt.wantService)
+				}
+			})
+		}
+	}
+}
+
+// TestReconcileConditionTransitions reconciles a source as its receive adapter gets a domain,
+// and checks that it becomes ready once its webhook is created.
+func TestReconcileConditionTransitions(t *testing.T) {
+	for _, kt := range testKinds {
+		t.Run(kt.name, func(t *testing.T) {
+			api := gstesting.NewGoogleAPI()
+			defer api.Close()
+			source := newSource(kt)
+			r := newTestReconciler(t, kt.kind(api), gcpSecret(), sink())
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			if cond := source.GetGSuiteStatus().GetCondition(sourcesv1alpha1.GSuiteSourceConditionServiceProvided); cond.Status != corev1.ConditionFalse {
+				t.Fatalf("ServiceProvided = %s, want False before the service has a domain", cond.Status)
+			}
+			if source.GetGSuiteStatus().IsReady() {
+				t.Fatalf("source is ready before the service has a domain")
+			}
+
+			ksvc, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+			if got := envValue(ksvc, resources.EnvLoggingLevel); got != "debug" {
+				t.Errorf("%s = %q, want the adapter log level of the config-logging ConfigMap", resources.EnvLoggingLevel, got)
+			}
+			markRoutesReady(ksvc)
+			if err := r.client.Update(ctx, ksvc); err != nil {
+				t.Fatalf("Update() = %v", err)
+			}
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			if !source.GetGSuiteStatus().IsReady() {
+				t.Fatalf("source is not ready: %+v", source.GetGSuiteStatus().Conditions)
+			}
+			webhook := source.GetGSuiteStatus().Webhooks[0]
+			channels := api.Channels()
+			if len(channels) != 1 || channels[0].Id != webhook.Id || channels[0].ResourceId != webhook.ResourceId {
+				t.Errorf("got channels %+v, want the one of webhook %+v", channels, webhook)
+			}
+			if want := "https://" + testDomain + "/"; len(channels) == 1 && !strings.HasPrefix(channels[0].Address, want) {
+				t.Errorf("channel address = %q, want it under %q", channels[0].Address, want)
+			}
+
+			// The source is deleted: its webhook is stopped, and its finalizer removed.
+			now := metav1.Now()
+			source.SetDeletionTimestamp(&now)
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			if got := len(api.Channels()); got != 0 {
+				t.Errorf("got %d channels after finalizing, want 0", got)
+			}
+			if hasFinalizer(source, testFinalizer) {
+				t.Errorf("finalizer not removed")
+			}
+		})
+	}
+}
+
+// TestReconcileWebhookDrift reconciles a ready source after the domain of its receive adapter changes,
+// and checks that its webhook is created again for the new domain, and the old one stopped.
+func TestReconcileWebhookDrift(t *testing.T) {
+	const newDomain = "test-source-adapter.default.example.org"
+	for _, kt := range testKinds {
+		t.Run(kt.name, func(t *testing.T) {
+			api := gstesting.NewGoogleAPI()
+			defer api.Close()
+			source := newSource(kt)
+			r := newTestReconciler(t, kt.kind(api), gcpSecret(), sink(), readyService(t, source))
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			old := source.GetGSuiteStatus().Webhooks[0]
+			if old.Domain != testDomain {
+				t.Fatalf("webhook domain = %q, want %q", old.Domain, testDomain)
+			}
+
+			ksvc, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+			ksvc.Status.Domain = newDomain
+			if err := r.client.Update(ctx, ksvc); err != nil {
+				t.Fatalf("Update() = %v", err)
+			}
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			webhook := source.GetGSuiteStatus().Webhooks[0]
+			if webhook.Id == old.Id || webhook.Domain != newDomain {
+				t.Errorf("got webhook %+v, want a new one for domain %q", webhook, newDomain)
+			}
+			channels := api.Channels()
+			if len(channels) != 1 || channels[0].Id != webhook.Id {
+				t.Fatalf("got channels %+v, want the one of webhook %+v", channels, webhook)
+			}
+			if want := "https://" + newDomain + "/"; !strings.HasPrefix(channels[0].Address, want) {
+				t.Errorf("channel address = %q, want it under %q", channels[0].Address, want)
+			}
+			if got := len(api.Requests(kt.stop)); got != 1 {
+				t.Errorf("got %d stop requests, want 1", got)
+			}
+
+			// Nothing changed, so the webhook is kept.
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			if got := source.GetGSuiteStatus().Webhooks[0].Id; got != webhook.Id {
+				t.Errorf("webhook Id = %s, want %s", got, webhook.Id)
+			}
+		})
+	}
+}
+
+// TestReconcileGmailTopicChange reconciles a GmailSource after its topic changes, first within the same project
+// and then to another one, and checks that the subscription of the user moves to the new topic, while the
+// watch of the mailbox is never stopped.
+func TestReconcileGmailTopicChange(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	source := newGmailSource()
+	r := newTestReconciler(t, gmailKind(api), gcpSecret(), sink(), readyService(t, source))
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, source); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	old := source.Status.Webhooks[0]
+
+	for _, topic := range []string{"projects/project-1/topics/gmail-2", "projects/project-2/topics/gmail"} {
+		source.Spec.Topic = topic
+		if _, err := r.Reconcile(ctx, source); err != nil {
+			t.Fatalf("Reconcile() = %v", err)
+		}
+		webhook := source.Status.Webhooks[0]
+		if webhook.ResourceId != topic {
+			t.Errorf("webhook topic = %q, want %q", webhook.ResourceId, topic)
+		}
+		// The subscription keeps its name within the same project.
+		project := strings.Split(topic, "/")[1]
+		if sameProject := strings.HasPrefix(old.Id, "projects/"+project+"/"); sameProject != (webhook.Id == old.Id) {
+			t.Errorf("webhook Id = %q, the one before was %q", webhook.Id, old.Id)
+		}
+		subscriptions := api.Subscriptions()
+		if len(subscriptions) != 1 || subscriptions[0].Name != webhook.Id || subscriptions[0].Topic != topic {
+			t.Errorf("Subscriptions() = %+v, want only %s to %s", subscriptions, webhook.Id, topic)
+		}
+		watches := api.Requests(gstesting.GmailUsersWatch)
+		if last := watches[len(watches)-1]; !strings.Contains(string(last.Body), topic) {
+			t.Errorf("users.watch = %s, want it to publish to %s", last.Body, topic)
+		}
+		if n := len(api.Requests(gstesting.GmailUsersStop)); n != 0 {
+			t.Errorf("got %d users.stop requests, want 0", n)
+		}
+		old = webhook
+	}
+}
+
+// TestReconcileGmailFinalizePermanentFailure finalizes a GmailSource whose subscription cannot be deleted,
+// and checks that it is not retried until the source is annotated to skip the stop.
+func TestReconcileGmailFinalizePermanentFailure(t *testing.T) {
+	api := gstesting.NewGoogleAPI()
+	defer api.Close()
+	source := newGmailSource()
+	r := newTestReconciler(t, gmailKind(api), gcpSecret(), sink(), readyService(t, source))
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, source); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	now := metav1.Now()
+	source.SetDeletionTimestamp(&now)
+	api.Script(gstesting.PubsubSubscriptionsDelete, gstesting.ErrorResponse(http.StatusForbidden, "User not authorized to perform this action."))
+	if _, err := r.Reconcile(ctx, source); err != nil {
+		t.Fatalf("Reconcile() = %v, want nil as retrying does not help", err)
+	}
+	cond := source.Status.GetCondition(sourcesv1alpha1.GSuiteSourceConditionFinalizing)
+	if cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != "WebHookStopFailed" {
+		t.Errorf("Finalizing condition = %+v, want False with reason WebHookStopFailed", cond)
+	}
+	if !hasFinalizer(source, testFinalizer) {
+		t.Fatalf("finalizer removed, want it kept until the webhook is stopped")
+	}
+
+	source.SetAnnotations(map[string]string{sourcesv1alpha1.SkipChannelStopAnnotation: "true"})
+	if _, err := r.Reconcile(ctx, source); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if hasFinalizer(source, testFinalizer) {
+		t.Errorf("finalizer kept, want it removed")
+	}
+	if n := len(api.Requests(gstesting.PubsubSubscriptionsDelete)); n != 1 {
+		t.Errorf("got %d subscription deletions, want 1", n)
+	}
+}
+
+// TestReconcileServiceDrift reconciles a source after its sink resolves to a new URI and the controller
+// runs with a new receive adapter image, and checks that its receive adapter is updated in place.
+func TestReconcileServiceDrift(t *testing.T) {
+	const (
+		newSinkHost     = "new-sink.default.svc.cluster.local"
+		newAdapterImage = "new-adapter-image"
+	)
+	for _, kt := range testKinds {
+		t.Run(kt.name, func(t *testing.T) {
+			api := gstesting.NewGoogleAPI()
+			defer api.Close()
+			source := newSource(kt)
+			s := sink()
+			r := newTestReconciler(t, kt.kind(api), gcpSecret(), s, readyService(t, source))
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			ksvc, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+
+			if err := unstructured.SetNestedField(s.Object, newSinkHost, "status", "address", "hostname"); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.client.Update(ctx, s); err != nil {
+				t.Fatalf("Update() = %v", err)
+			}
+			r.receiveAdapterImage = newAdapterImage
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			updated, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+			if updated.Name != ksvc.Name {
+				t.Errorf("service %q replaced by %q, want it updated in place", ksvc.Name, updated.Name)
+			}
+			if got := envValue(updated, "SINK"); !strings.Contains(got, newSinkHost) {
+				t.Errorf("SINK = %q, want the URI of %q", got, newSinkHost)
+			}
+			if got := updated.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image; got != newAdapterImage {
+				t.Errorf("image = %q, want %q", got, newAdapterImage)
+			}
+		})
+	}
+}
+
+// TestReconcileRenewalChannels renews the webhook of a source, and checks that the renewed channel is saved
+// in the channel tokens secret, which the receive adapter reads it from, without updating the receive adapter.
+func TestReconcileRenewalChannels(t *testing.T) {
+	for _, kt := range testKinds {
+		t.Run(kt.name, func(t *testing.T) {
+			api := gstesting.NewGoogleAPI()
+			defer api.Close()
+			source := newSource(kt)
+			r := newTestReconciler(t, kt.kind(api), gcpSecret(), sink(), readyService(t, source))
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			ksvc, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+			old := source.GetGSuiteStatus().Webhooks[0]
+
+			// The webhook is about to expire.
+			expiration := metav1.NewTime(time.Now().Add(time.Minute))
+			source.GetGSuiteStatus().Webhooks[0].Expiration = &expiration
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			webhook := source.GetGSuiteStatus().Webhooks[0]
+			if webhook.Id == old.Id {
+				t.Fatalf("webhook Id = %s, want a renewed one", webhook.Id)
+			}
+
+			tokens := &corev1.Secret{}
+			if err := r.client.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: resources.TokenSecretName(kt.name, source)}, tokens); err != nil {
+				t.Fatalf("Get() = %v", err)
+			}
+			channels := make(map[string]string)
+			if err := json.Unmarshal(tokens.Data[resources.ChannelsKey], &channels); err != nil {
+				t.Fatalf("invalid channels %q: %v", tokens.Data[resources.ChannelsKey], err)
+			}
+			if want := map[string]string{gsuite.CursorKey(testEmail, kt.resource): webhook.Id}; !reflect.DeepEqual(channels, want) {
+				t.Errorf("channels = %v, want %v", channels, want)
+			}
+
+			renewed, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+			if !reflect.DeepEqual(renewed.Spec, ksvc.Spec) {
+				t.Errorf("service spec changed on renewal, want no new revision of the receive adapter")
+			}
+		})
+	}
+}
+
+// TestReconcileCursors reconciles a source whose receive adapter saved a cursor, and checks that the cursors
+// ConfigMap keeps it, while the cursors of the resources no longer watched are removed.
+func TestReconcileCursors(t *testing.T) {
+	for _, kt := range testKinds {
+		t.Run(kt.name, func(t *testing.T) {
+			api := gstesting.NewGoogleAPI()
+			defer api.Close()
+			source := newSource(kt)
+			r := newTestReconciler(t, kt.kind(api), gcpSecret(), sink(), readyService(t, source))
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			configMaps := r.configMaps.ConfigMaps(testNamespace)
+			name := resources.CursorsConfigMapName(kt.name, source)
+			cm, err := configMaps.Get(name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Get(%q) = %v", name, err)
+			}
+			key := gsuite.CursorKey(testEmail, kt.resource)
+			webhook := source.GetGSuiteStatus().Webhooks[0]
+			if got := cm.Data[key]; got != webhook.Cursor {
+				t.Errorf("cursor = %q, want the one of webhook %+v", got, webhook)
+			}
+			if !metav1.IsControlledBy(cm, source) {
+				t.Errorf("ConfigMap %q is not owned by the source", name)
+			}
+
+			// The receive adapter saves its progress, and the controller runs with a new receive
+			// adapter image, so that the receive adapter restarts.
+			cm.Data[key] = "saved"
+			cm.Data[gsuite.CursorKey(testEmail, "unwatched")] = "stale"
+			if _, err := configMaps.Update(cm); err != nil {
+				t.Fatalf("Update() = %v", err)
+			}
+			r.receiveAdapterImage = "new-adapter-image"
+
+			if _, err := r.Reconcile(ctx, source); err != nil {
+				t.Fatalf("Reconcile() = %v", err)
+			}
+			cm, err = configMaps.Get(name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Get(%q) = %v", name, err)
+			}
+			if want := map[string]string{key: "saved"}; !reflect.DeepEqual(cm.Data, want) {
+				t.Errorf("cursors = %v, want %v", cm.Data, want)
+			}
+
+			ksvc, err := r.getService(ctx, source)
+			if err != nil {
+				t.Fatalf("getService() = %v", err)
+			}
+			if strings.Contains(envValue(ksvc, resources.EnvWatches), `"cursor"`) {
+				t.Errorf("%s = %q, want it without the cursors", resources.EnvWatches, envValue(ksvc, resources.EnvWatches))
+			}
+			if got := envValue(ksvc, resources.EnvCursorsConfigMap); got != name {
+				t.Errorf("%s = %q, want %q", resources.EnvCursorsConfigMap, got, name)
+			}
+			serviceAccount := resources.AdapterServiceAccountName(kt.name, source)
+			if got := ksvc.Spec.RunLatest.Configuration.RevisionTemplate.Spec.ServiceAccountName; got != serviceAccount {
+				t.Errorf("service account = %q, want %q", got, serviceAccount)
+			}
+			objectKey := client.ObjectKey{Namespace: testNamespace, Name: serviceAccount}
+			for _, object := range []runtime.Object{&corev1.ServiceAccount{}, &rbacv1.Role{}, &rbacv1.RoleBinding{}} {
+				if err := r.client.Get(ctx, objectKey, object); err != nil {
+					t.Errorf("Get(%T %q) = %v", object, serviceAccount, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAllRequests(t *testing.T) {
+	store := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+	for _, source := range []*sourcesv1alpha1.DriveSource{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "source-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "source-2"}},
+	} {
+		if err := store.Add(source); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := allRequests(store)
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].Name < got[j].Name
+	})
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: "source-1"}},
+		{NamespacedName: types.NamespacedName{Namespace: "other", Name: "source-2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("allRequests() = %v, want %v", got, want)
+	}
+}
+
+// newTestReconciler returns a reconciler of the kind with a fake client holding the given objects.
+// The G Suite clients authenticate with the fake credentials of the gcpSecret, if any.
+func newTestReconciler(t *testing.T, kind gsuite.Kind, objects ...runtime.Object) *reconciler {
+	t.Helper()
+	tracker := clienttesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	if err := tracker.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testSystemNS, Name: resources.LoggingConfigMapName},
+		Data:       map[string]string{"loglevel." + resources.LoggingComponentAdapter: "debug"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	kubeFake := &clienttesting.Fake{}
+	kubeFake.AddReactor("patch", "configmaps", patchConfigMapReaction(tracker))
+	kubeFake.AddReactor("*", "*", clienttesting.ObjectReaction(tracker))
+
+	return &reconciler{
+		kind:                kind,
+		finalizerName:       testFinalizer,
+		client:              fake.NewFakeClient(objects...),
+		scheme:              scheme.Scheme,
+		configMaps:          &fakecorev1.FakeCoreV1{Fake: kubeFake},
+		systemNamespace:     testSystemNS,
+		receiveAdapterImage: testAdapterImage,
+	}
+}
+
+// patchConfigMapReaction patches the ConfigMaps of the tracker. The ObjectReaction of client-go decodes the patched
+// object over the current one, so it keeps the keys the patches remove.
+func patchConfigMapReaction(tracker clienttesting.ObjectTracker) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		obj, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		current, err := json.Marshal(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		patched, err := strategicpatch.StrategicMergePatch(current, patch.GetPatch(), &corev1.ConfigMap{})
+		if err != nil {
+			return true, nil, err
+		}
+		cm := &corev1.ConfigMap{}
+		if err := json.Unmarshal(patched, cm); err != nil {
+			return true, nil, err
+		}
+		return true, cm, tracker.Update(patch.GetResource(), cm, patch.GetNamespace())
+	}
+}
+
+// gmailKind returns the Gmail kind with its Gmail and Pub/Sub clients pointed at the fake API.
+func gmailKind(api *gstesting.GoogleAPI) gsuite.Kind {
+	return gmail.Kind{Endpoint: api.GmailEndpoint(), PubsubEndpoint: api.PubsubEndpoint(), HTTPClient: api.Client()}
+}
+
+// newGmailSource returns a GmailSource of the test user, which publishes to a topic of project-1.
+func newGmailSource() *sourcesv1alpha1.GmailSource {
+	source := newSource(testKind{
+		name: "gmail",
+		newSource: func() sourcesv1alpha1.GSuiteSource {
+			return &sourcesv1alpha1.GmailSource{
+				TypeMeta: metav1.TypeMeta{APIVersion: sourcesv1alpha1.SchemeGroupVersion.String(), Kind: "GmailSource"},
+				Spec:     sourcesv1alpha1.GmailSourceSpec{Topic: "projects/project-1/topics/gmail"},
+			}
+		},
+	})
+	return source.(*sourcesv1alpha1.GmailSource)
+}
+
+func newSource(kt testKind) sourcesv1alpha1.GSuiteSource {
+	source := kt.newSource()
+	source.SetNamespace(testNamespace)
+	source.SetName(testSourceName)
+	source.SetUID(testSourceUID)
+	spec := source.GetGSuiteSpec()
+	spec.EmailAddress = testEmail
+	spec.GcpCredsSecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: testSecretName},
+		Key:                  testSecretKey,
+	}
+	spec.Sink = &corev1.ObjectReference{
+		APIVersion: "eventing.knative.dev/v1alpha1",
+		Kind:       "Channel",
+		Name:       testSinkName,
+	}
+	return source
+}
+
+// anyKind adapts a mutation of the source that does not depend on its kind.
+func anyKind(mutate func(source sourcesv1alpha1.GSuiteSource)) func(kt testKind, source sourcesv1alpha1.GSuiteSource) {
+	return func(kt testKind, source sourcesv1alpha1.GSuiteSource) {
+		mutate(source)
+	}
+}
+
+// withReadyWebhook returns a mutation that gives the source a webhook for the default resource of the test user,
+// expiring in the given duration, as if it was created by a previous reconciliation.
+func withReadyWebhook(expiresIn time.Duration) func(kt testKind, source sourcesv1alpha1.GSuiteSource) {
+	return func(kt testKind, source sourcesv1alpha1.GSuiteSource) {
+		source.SetFinalizers([]string{testFinalizer})
+		expiration := metav1.NewTime(time.Now().Add(expiresIn))
+		status := source.GetGSuiteStatus()
+		status.Webhooks = []sourcesv1alpha1.Webhook{{
+			EmailAddress: testEmail,
+			Resource:     kt.resource,
+			Cursor:       "cursor",
+			Id:           testChannelId,
+			ResourceId:   testResourceId,
+			Expiration:   &expiration,
+			Domain:       testDomain,
+		}}
+		status.InitializeConditions(false)
+		status.MarkWebHook()
+	}
+}
+
+// deleteWithWebhook returns a mutation that marks the source as deleted, with a webhook
+// expiring in the given duration to stop.
+func deleteWithWebhook(expiresIn time.Duration) func(source sourcesv1alpha1.GSuiteSource) {
+	return func(source sourcesv1alpha1.GSuiteSource) {
+		now := metav1.Now()
+		source.SetDeletionTimestamp(&now)
+		source.SetFinalizers([]string{testFinalizer})
+		expiration := metav1.NewTime(time.Now().Add(expiresIn))
+		source.GetGSuiteStatus().Webhooks = []sourcesv1alpha1.Webhook{{
+			EmailAddress: testEmail,
+			Id:           testChannelId,
+			ResourceId:   testResourceId,
+			Expiration:   &expiration,
+		}}
+	}
+}
+
+func gcpSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSecretName},
+		Data:       map[string][]byte{testSecretKey: []byte(gstesting.Credentials)},
+	}
+}
+
+// sink returns an addressable sink.
+func sink() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "eventing.knative.dev/v1alpha1",
+		"kind":       "Channel",
+		"metadata": map[string]interface{}{
+			"namespace": testNamespace,
+			"name":      testSinkName,
+		},
+		"status": map[string]interface{}{
+			"address": map[string]interface{}{
+				"hostname": testSinkHost,
+			},
+		},
+	}}
+}
+
+// readyService returns a receive adapter service of the source with a routed domain.
+func readyService(t *testing.T, source sourcesv1alpha1.GSuiteSource) *servingv1alpha1.Service {
+	t.Helper()
+	ksvc := &servingv1alpha1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testSourceName + "-adapter"},
+	}
+	if err := controllerutil.SetControllerReference(source, ksvc, scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	markRoutesReady(ksvc)
+	return ksvc
+}
+
+func markRoutesReady(ksvc *servingv1alpha1.Service) {
+	ksvc.Status.Domain = testDomain
+	ksvc.Status.Conditions = duckv1alpha1.Conditions{{
+		Type:   servingv1alpha1.ServiceConditionRoutesReady,
+		Status: corev1.ConditionTrue,
+	}}
+}
+
+func hasFinalizer(source sourcesv1alpha1.GSuiteSource, finalizer string) bool {
+	for _, f := range source.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
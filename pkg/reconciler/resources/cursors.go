@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CursorsConfigMapName returns the name of the ConfigMap holding the cursors of the resources the given source
+// watches. The controller adds the cursors of the new resources, and the receive adapter saves them as it reads
+// their changes, so that it does not read the same changes again when it restarts.
+func CursorsConfigMapName(kind string, source sourcesv1alpha1.GSuiteSource) string {
+	return fmt.Sprintf("%s-%s-cursors", source.GetName(), kind)
+}
+
+// MakeCursorsConfigMap generates, but does not create, the ConfigMap holding the given cursors of the given source,
+// by gsuite.CursorKey.
+func MakeCursorsConfigMap(kind string, source sourcesv1alpha1.GSuiteSource, cursors map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CursorsConfigMapName(kind, source),
+			Namespace: source.GetNamespace(),
+			Labels: map[string]string{
+				"receive-adapter": kind,
+			},
+		},
+		Data: cursors,
+	}
+}
+
+// AdapterServiceAccountName returns the name of the ServiceAccount the receive adapter of the given source runs as,
+// and of the Role and RoleBinding that allow it to save its cursors.
+func AdapterServiceAccountName(kind string, source sourcesv1alpha1.GSuiteSource) string {
+	return fmt.Sprintf("%s-%s-adapter", source.GetName(), kind)
+}
+
+// MakeAdapterServiceAccount generates, but does not create, the ServiceAccount of the receive adapter of the given source.
+func MakeAdapterServiceAccount(kind string, source sourcesv1alpha1.GSuiteSource) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: adapterObjectMeta(kind, source),
+	}
+}
+
+// MakeAdapterRole generates, but does not create, the Role that allows the receive adapter of the given source
+// to read and save its cursors, and nothing else.
+func MakeAdapterRole(kind string, source sourcesv1alpha1.GSuiteSource) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: adapterObjectMeta(kind, source),
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{CursorsConfigMapName(kind, source)},
+			Verbs:         []string{"get", "patch"},
+		}},
+	}
+}
+
+// MakeAdapterRoleBinding generates, but does not create, the RoleBinding of the Role of the receive adapter
+// of the given source to its ServiceAccount.
+func MakeAdapterRoleBinding(kind string, source sourcesv1alpha1.GSuiteSource) *rbacv1.RoleBinding {
+	name := AdapterServiceAccountName(kind, source)
+	return &rbacv1.RoleBinding{
+		ObjectMeta: adapterObjectMeta(kind, source),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      name,
+			Namespace: source.GetNamespace(),
+		}},
+	}
+}
+
+func adapterObjectMeta(kind string, source sourcesv1alpha1.GSuiteSource) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      AdapterServiceAccountName(kind, source),
+		Namespace: source.GetNamespace(),
+		Labels: map[string]string{
+			"receive-adapter": kind,
+		},
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// tokenKeyPrefix is the prefix of the keys of the channel tokens secret with the tokens.
+	tokenKeyPrefix = "token-"
+
+	// ChannelsKey is the key of the channel tokens secret with the JSON object of the IDs of the channels
+	// of the webhooks, by the gsuite.CursorKey of their resources. The receive adapter only accepts the
+	// notifications of those channels, and it sees the renewed ones without restarting.
+	ChannelsKey = "channels"
+)
+
+// TokenKey returns the key of the n-th channel token of a source.
+// Every time webhooks are created, they use the latest token, and a new one is added to the secret.
+// The receive adapter accepts the tokens in advance, so that it does not reject notifications
+// before it sees the rotated secret. The tokens no longer used by any webhook are removed.
+func TokenKey(n int) string {
+	return fmt.Sprintf("%s%d", tokenKeyPrefix, n)
+}
+
+// TokenIndex returns the index of the given channel token key, or -1 if it is not a token key.
+func TokenIndex(key string) int {
+	if !strings.HasPrefix(key, tokenKeyPrefix) {
+		return -1
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(key, tokenKeyPrefix))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// Channels returns the value of the ChannelsKey of the channel tokens secret of the given source.
+func Channels(source sourcesv1alpha1.GSuiteSource) ([]byte, error) {
+	channels := make(map[string]string)
+	for _, webhook := range source.GetGSuiteStatus().Webhooks {
+		if webhook.Id != "" && !webhook.Polled {
+			channels[gsuite.CursorKey(webhook.EmailAddress, webhook.Resource)] = webhook.Id
+		}
+	}
+	return json.Marshal(channels)
+}
+
+// TokenSecretName returns the name of the secret holding the channel tokens of the given source.
+func TokenSecretName(kind string, source sourcesv1alpha1.GSuiteSource) string {
+	return fmt.Sprintf("%s-%s-channel-token", source.GetName(), kind)
+}
+
+// MakeTokenSecret generates, but does not create, the Secret holding the channel tokens of the given source.
+func MakeTokenSecret(kind string, source sourcesv1alpha1.GSuiteSource, token string) *corev1.Secret {
+	labels := map[string]string{
+		"receive-adapter": kind,
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TokenSecretName(kind, source),
+			Namespace: source.GetNamespace(),
+			Labels:    labels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			TokenKey(1): []byte(token),
+		},
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	sourcesv1alpha1 "github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+	"github.com/nachocano/gsuite-source/pkg/gsuite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	credsVolume    = "google-cloud-key"
+	credsMountPath = "/var/secrets/google"
+
+	tokenVolume    = "channel-token"
+	tokenMountPath = "/var/secrets/channel"
+
+	// metricsPort is the port the receive adapter exposes its metrics on.
+	metricsPort = "9095"
+
+	// EnvWatches is the environment variable of the receive adapter with the users and resources to watch.
+	EnvWatches = "WATCHES"
+	// EnvCursorsConfigMap is the environment variable of the receive adapter with the name of the ConfigMap
+	// holding the cursors of the watched resources.
+	EnvCursorsConfigMap = "CURSORS_CONFIGMAP"
+	// EnvPollInterval is the environment variable of the receive adapter with the poll interval in poll mode.
+	EnvPollInterval = "POLL_INTERVAL"
+	// EnvCloudEventsSpecVersion and EnvCloudEventsMode are the environment variables of the receive adapter
+	// with the spec version and HTTP content mode of the CloudEvents.
+	EnvCloudEventsSpecVersion = "CE_SPEC_VERSION"
+	EnvCloudEventsMode        = "CE_MODE"
+	// EnvDeadLetterSink, EnvRetries and EnvBackoffDelay are the environment variables of the receive adapter
+	// with the dead letter sink, if any, and how the failed deliveries are retried.
+	EnvDeadLetterSink = "DEAD_LETTER_SINK"
+	EnvRetries        = "RETRIES"
+	EnvBackoffDelay   = "BACKOFF_DELAY"
+	// EnvLoggingConfig and EnvLoggingLevel are the environment variables of the receive adapter
+	// with the zap logger config and the log level from the config-logging ConfigMap.
+	EnvLoggingConfig = "LOGGING_CONFIG"
+	EnvLoggingLevel  = "LOGGING_LEVEL"
+
+	// LoggingConfigMapName is the name of the ConfigMap with the logging config of the controller
+	// and the receive adapters, in the namespace of the controller.
+	LoggingConfigMapName = "config-logging"
+	// LoggingComponentController and LoggingComponentAdapter are the components of the config-logging
+	// ConfigMap, whose log levels are set with the loglevel.<component> keys.
+	LoggingComponentController = "controller"
+	LoggingComponentAdapter    = "adapter"
+	// loggingConfigKey is the key of the config-logging ConfigMap with the zap logger config.
+	loggingConfigKey = "zap-logger-config"
+
+	// MinScaleAnnotation is the Knative annotation with the minimum number of replicas of a revision.
+	MinScaleAnnotation = "autoscaling.knative.dev/minScale"
+)
+
+// MakeService generates, but does not create, a Service for the given G Suite source of the given kind.
+// If the receive adapter polls any resource, it is never scaled to zero. In poll mode, it polls all of them.
+// The receive adapter logs with the config in the data of the config-logging ConfigMap, if any.
+func MakeService(kind string, source sourcesv1alpha1.GSuiteSource, receiveAdapterImage string, polled bool, loggingConfig map[string]string) (*servingv1alpha1.Service, error) {
+	labels := map[string]string{
+		"receive-adapter": kind,
+	}
+	spec := source.GetGSuiteSpec()
+	sinkURI := source.GetGSuiteStatus().SinkURI
+	watches, err := watchesFrom(source)
+	if err != nil {
+		return nil, err
+	}
+	ce := spec.GetCloudEvents()
+	delivery := spec.GetDelivery()
+	// The metrics of the receive adapter are scraped from its pods.
+	annotations := map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   metricsPort,
+	}
+	if polled {
+		annotations[MinScaleAnnotation] = "1"
+	}
+	env := []corev1.EnvVar{
+		{
+			Name:  "NAME",
+			Value: source.GetName(),
+		},
+		{
+			Name:  "NAMESPACE",
+			Value: source.GetNamespace(),
+		},
+		{
+			Name:  "SINK",
+			Value: sinkURI,
+		},
+		{
+			Name:  EnvWatches,
+			Value: watches,
+		},
+		{
+			Name:  EnvCursorsConfigMap,
+			Value: CursorsConfigMapName(kind, source),
+		},
+		{
+			Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+			Value: fmt.Sprintf("%s/%s", credsMountPath, spec.GcpCredsSecret.Key),
+		},
+		{
+			Name:  "CHANNEL_TOKENS_DIR",
+			Value: tokenMountPath,
+		},
+		{
+			Name:  "METRICS_PORT",
+			Value: metricsPort,
+		},
+		{
+			Name:  EnvCloudEventsSpecVersion,
+			Value: ce.SpecVersion,
+		},
+		{
+			Name:  EnvCloudEventsMode,
+			Value: string(ce.Mode),
+		},
+		{
+			Name:  EnvRetries,
+			Value: strconv.Itoa(int(*delivery.Retries)),
+		},
+		{
+			Name:  EnvBackoffDelay,
+			Value: delivery.BackoffDelay.Duration.String(),
+		},
+		{
+			Name:  EnvLoggingConfig,
+			Value: loggingConfig[loggingConfigKey],
+		},
+		{
+			Name:  EnvLoggingLevel,
+			Value: loggingConfig["loglevel."+LoggingComponentAdapter],
+		},
+	}
+	if uri := source.GetGSuiteStatus().DeadLetterSinkURI; uri != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  EnvDeadLetterSink,
+			Value: uri,
+		})
+	}
+	if spec.IsPolling() {
+		interval := sourcesv1alpha1.DefaultPollInterval
+		if spec.PollInterval != nil {
+			interval = spec.PollInterval.Duration
+		}
+		env = append(env, corev1.EnvVar{
+			Name:  EnvPollInterval,
+			Value: interval.String(),
+		})
+	}
+
+	ksvc := &servingv1alpha1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", source.GetName()),
+			Namespace:    source.GetNamespace(),
+			Labels:       labels,
+		},
+		Spec: servingv1alpha1.ServiceSpec{
+			RunLatest: &servingv1alpha1.RunLatestType{
+				Configuration: servingv1alpha1.ConfigurationSpec{
+					RevisionTemplate: servingv1alpha1.RevisionTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: annotations,
+						},
+						Spec: servingv1alpha1.RevisionSpec{
+							// The receive adapter saves its cursors with the permissions of its service account.
+							ServiceAccountName: AdapterServiceAccountName(kind, source),
+							Container: corev1.Container{
+								Image: receiveAdapterImage,
+								Env:   env,
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      credsVolume,
+										MountPath: credsMountPath,
+									},
+									{
+										Name:      tokenVolume,
+										MountPath: tokenMountPath,
+										ReadOnly:  true,
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: credsVolume,
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: spec.GcpCredsSecret.Name,
+										},
+									},
+								},
+								{
+									Name: tokenVolume,
+									VolumeSource: corev1.VolumeSource{
+										// Mounted rather than passed through env, so that the adapter sees the rotated tokens.
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: TokenSecretName(kind, source),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return ksvc, nil
+}
+
+// watchesFrom returns the JSON array with the users and resources the source watches. Their cursors and channels
+// are not part of it, as the receive adapter reads them from the cursors ConfigMap and the channel tokens secret,
+// so that it is not updated every time a channel is renewed.
+func watchesFrom(source sourcesv1alpha1.GSuiteSource) (string, error) {
+	watches := make([]gsuite.Watch, 0, len(source.GetGSuiteStatus().Webhooks))
+	for _, webhook := range source.GetGSuiteStatus().Webhooks {
+		watches = append(watches, gsuite.Watch{
+			EmailAddress: webhook.EmailAddress,
+			Resource:     webhook.Resource,
+		})
+	}
+	b, err := json.Marshal(watches)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,636 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testing provides fakes of the Google APIs for the tests of the G Suite sources.
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gsdrive "google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// The methods of the Google APIs implemented by GoogleAPI.
+const (
+	DriveChangesGetStartPageToken = "drive.changes.getStartPageToken"
+	DriveChangesWatch             = "drive.changes.watch"
+	DriveChangesList              = "drive.changes.list"
+	DriveChannelsStop             = "drive.channels.stop"
+	DriveFilesWatch               = "drive.files.watch"
+	CalendarEventsWatch           = "calendar.events.watch"
+	CalendarEventsList            = "calendar.events.list"
+	CalendarChannelsStop          = "calendar.channels.stop"
+	GmailUsersGetProfile          = "gmail.users.getProfile"
+	GmailUsersWatch               = "gmail.users.watch"
+	GmailUsersStop                = "gmail.users.stop"
+	GmailUsersHistoryList         = "gmail.users.history.list"
+	PubsubSubscriptionsCreate     = "pubsub.projects.subscriptions.create"
+	PubsubSubscriptionsGet        = "pubsub.projects.subscriptions.get"
+	PubsubSubscriptionsDelete     = "pubsub.projects.subscriptions.delete"
+	PubsubSubscriptionsList       = "pubsub.projects.subscriptions.list"
+	PubsubModifyPushConfig        = "pubsub.projects.subscriptions.modifyPushConfig"
+	ReportsActivitiesWatch        = "reports.activities.watch"
+	ReportsChannelsStop           = "reports.channels.stop"
+
+	drivePath    = "/drive/v3/"
+	calendarPath = "/calendar/v3/"
+	gmailPath    = "/gmail/v1/users/me/"
+	pubsubPath   = "/pubsub/v1/"
+	adminPath    = "/admin/"
+
+	// StartPageToken is the Drive Changes start page token returned by default.
+	StartPageToken = "1"
+	// SyncToken is the Calendar Events sync token returned by default to the full synchronizations.
+	SyncToken = "sync-token"
+	// HistoryId is the Gmail history ID of the mailbox returned by default.
+	HistoryId = "1"
+	// DefaultChannelExpiration is the expiration granted by default to the channels that do not request one.
+	DefaultChannelExpiration = time.Hour
+)
+
+// Credentials are fake service account credentials, which the G Suite kinds can build their clients with.
+// The clients never authenticate, as they are pointed at GoogleAPI with its own HTTP client.
+const Credentials = `{
+  "type": "service_account",
+  "project_id": "fake-project",
+  "private_key_id": "fake-key",
+  "private_key": "fake",
+  "client_email": "fake@fake-project.iam.gserviceaccount.com",
+  "client_id": "1",
+  "token_uri": "https://oauth2.googleapis.com/token"
+}`
+
+// Response is a scripted response of GoogleAPI.
+type Response struct {
+	// Code is the HTTP status code of the response. It defaults to 200.
+	Code int
+	// Body is encoded as the JSON body of the response, if not nil.
+	Body interface{}
+}
+
+// ErrorResponse returns a response with a Google API error of the given HTTP status code.
+func ErrorResponse(code int, message string) Response {
+	return Response{
+		Code: code,
+		Body: map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    code,
+				"message": message,
+			},
+		},
+	}
+}
+
+// Request is a request received by GoogleAPI.
+type Request struct {
+	// Method is the Google API method, e.g., DriveChangesList.
+	Method string
+	// CalendarId is the calendar of the Calendar Events calls.
+	CalendarId string
+	// FileId is the file of the Drive Files calls.
+	FileId string
+	// UserKey and Application are the user and application of the Reports Activities calls.
+	UserKey     string
+	Application string
+	// Subscription is the name of the subscription of the Pub/Sub subscriptions calls, or
+	// the project of the subscriptions.list calls.
+	Subscription string
+	Query        url.Values
+	Body         []byte
+}
+
+// Channel is a push notification channel created with GoogleAPI.
+type Channel struct {
+	Id          string
+	ResourceId  string
+	ResourceURI string
+	Token       string
+	Address     string
+	Expiration  time.Time
+	// CalendarId is the watched calendar of the Calendar channels.
+	CalendarId string
+	// FileId is the watched file of the Drive Files channels.
+	FileId string
+	// Application is the watched application of the Reports channels.
+	Application string
+}
+
+// Subscription is a Pub/Sub push subscription created with GoogleAPI.
+type Subscription struct {
+	Name         string
+	Topic        string
+	PushEndpoint string
+}
+
+// Notification is a push notification sent by GoogleAPI.
+type Notification struct {
+	ChannelId     string
+	Token         string
+	ResourceId    string
+	ResourceURI   string
+	ResourceState string
+	MessageNumber int
+	// Body is the payload of the notification, if any, e.g., a Reports activity.
+	Body []byte
+}
+
+// GoogleAPI is an in-process fake of the Drive, Calendar, Gmail, Reports and Pub/Sub APIs. By default, it creates and
+// stops channels and subscriptions, and returns no changes nor events. The responses of any method can be
+// scripted, e.g., to return errors.
+type GoogleAPI struct {
+	server *httptest.Server
+
+	// mu guards the fields below, as the fake serves concurrent requests.
+	mu        sync.Mutex
+	responses map[string][]Response
+	requests  []Request
+	channels  map[string]*Channel
+	// subscriptions are the Pub/Sub subscriptions by name.
+	subscriptions map[string]*Subscription
+	// resources is the number of channels created, which is used to generate the resource IDs.
+	resources int
+	// messageNumbers are the numbers of the last notification sent on each channel.
+	messageNumbers map[string]int
+}
+
+// NewGoogleAPI starts a fake Google API server. It must be closed when done.
+func NewGoogleAPI() *GoogleAPI {
+	f := &GoogleAPI{
+		responses:      make(map[string][]Response),
+		channels:       make(map[string]*Channel),
+		subscriptions:  make(map[string]*Subscription),
+		messageNumbers: make(map[string]int),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Close shuts the server down.
+func (f *GoogleAPI) Close() {
+	f.server.Close()
+}
+
+// URL returns the base URL of the server.
+func (f *GoogleAPI) URL() string {
+	return f.server.URL
+}
+
+// DriveOptions returns the client options that point the Drive API clients at the server, e.g.,
+// to set the ClientOptions of the Drive kind.
+func (f *GoogleAPI) DriveOptions() []option.ClientOption {
+	return f.options(drivePath)
+}
+
+// CalendarOptions returns the client options that point the Calendar API clients at the server, e.g.,
+// to set the ClientOptions of the Calendar kind.
+func (f *GoogleAPI) CalendarOptions() []option.ClientOption {
+	return f.options(calendarPath)
+}
+
+// GmailEndpoint returns the base URL of the Gmail API of the server, e.g., to set the Endpoint of the Gmail kind.
+func (f *GoogleAPI) GmailEndpoint() string {
+	return f.server.URL + gmailPath
+}
+
+// PubsubEndpoint returns the base URL of the Pub/Sub API of the server, e.g., to set the PubsubEndpoint
+// of the Gmail kind.
+func (f *GoogleAPI) PubsubEndpoint() string {
+	return f.server.URL + pubsubPath
+}
+
+// AdminEndpoint returns the base URL of the Admin SDK APIs of the server, e.g., to set the Endpoint
+// of the Reports kind.
+func (f *GoogleAPI) AdminEndpoint() string {
+	return f.server.URL + adminPath
+}
+
+// Client returns an HTTP client for the server.
+func (f *GoogleAPI) Client() *http.Client {
+	return f.server.Client()
+}
+
+func (f *GoogleAPI) options(path string) []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(f.server.URL + path),
+		option.WithHTTPClient(f.server.Client()),
+	}
+}
+
+// Script queues responses for the given method, which are returned in order, before the default ones.
+// The channels of the scripted watch responses are not recorded.
+func (f *GoogleAPI) Script(method string, responses ...Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[method] = append(f.responses[method], responses...)
+}
+
+// Requests returns the requests received for the given method, in order.
+func (f *GoogleAPI) Requests(method string) []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var requests []Request
+	for _, r := range f.requests {
+		if r.Method == method {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// Channels returns the channels created and not stopped yet.
+func (f *GoogleAPI) Channels() []Channel {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	channels := make([]Channel, 0, len(f.channels))
+	for _, c := range f.channels {
+		channels = append(channels, *c)
+	}
+	return channels
+}
+
+// Subscriptions returns the Pub/Sub subscriptions created and not deleted yet, sorted by name.
+func (f *GoogleAPI) Subscriptions() []Subscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	subscriptions := make([]Subscription, 0, len(f.subscriptions))
+	for _, s := range f.subscriptions {
+		subscriptions = append(subscriptions, *s)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].Name < subscriptions[j].Name
+	})
+	return subscriptions
+}
+
+// NotifyChannel sends a push notification with the given resource state, e.g., "sync" or "exists",
+// on the channel with the given ID, to the address it was created with.
+func (f *GoogleAPI) NotifyChannel(channelId, resourceState string) (*http.Response, error) {
+	return f.NotifyChannelWithBody(channelId, resourceState, nil)
+}
+
+// NotifyChannelWithBody sends a push notification like NotifyChannel, with the given payload, e.g.,
+// the activity of a Reports notification encoded as JSON.
+func (f *GoogleAPI) NotifyChannelWithBody(channelId, resourceState string, body []byte) (*http.Response, error) {
+	f.mu.Lock()
+	channel, ok := f.channels[channelId]
+	if !ok {
+		f.mu.Unlock()
+		return nil, fmt.Errorf("unknown channel %q", channelId)
+	}
+	f.messageNumbers[channelId]++
+	n := Notification{
+		ChannelId:     channel.Id,
+		Token:         channel.Token,
+		ResourceId:    channel.ResourceId,
+		ResourceURI:   channel.ResourceURI,
+		ResourceState: resourceState,
+		MessageNumber: f.messageNumbers[channelId],
+		Body:          body,
+	}
+	address := channel.Address
+	f.mu.Unlock()
+	return Notify(address, n)
+}
+
+// Notify sends a push notification to the given address, e.g., the URL of a receive adapter.
+func Notify(address string, n Notification) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(n.Body))
+	if err != nil {
+		return nil, err
+	}
+	if len(n.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	}
+	req.Header.Set("X-Goog-Channel-ID", n.ChannelId)
+	req.Header.Set("X-Goog-Message-Number", strconv.Itoa(n.MessageNumber))
+	req.Header.Set("X-Goog-Resource-ID", n.ResourceId)
+	req.Header.Set("X-Goog-Resource-State", n.ResourceState)
+	if n.Token != "" {
+		req.Header.Set("X-Goog-Channel-Token", n.Token)
+	}
+	if n.ResourceURI != "" {
+		req.Header.Set("X-Goog-Resource-URI", n.ResourceURI)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (f *GoogleAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+	req := route(r.Method, r.URL)
+	req.Query, req.Body = r.URL.Query(), body
+	if req.Method == "" {
+		writeJSON(w, ErrorResponse(http.StatusNotFound, fmt.Sprintf("unknown method %s %s", r.Method, r.URL.Path)))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	if scripted := f.responses[req.Method]; len(scripted) > 0 {
+		f.responses[req.Method] = scripted[1:]
+		writeJSON(w, scripted[0])
+		return
+	}
+	writeJSON(w, f.defaultResponse(req))
+}
+
+// route returns the request with the Google API method of a request, and its calendar, file, application or
+// subscription, if any.
+func route(method string, u *url.URL) Request {
+	path := u.EscapedPath()
+	switch {
+	case strings.HasPrefix(path, drivePath):
+		p := strings.TrimPrefix(path, drivePath)
+		switch {
+		case method == http.MethodGet && p == "changes/startPageToken":
+			return Request{Method: DriveChangesGetStartPageToken}
+		case method == http.MethodPost && p == "changes/watch":
+			return Request{Method: DriveChangesWatch}
+		case method == http.MethodGet && p == "changes":
+			return Request{Method: DriveChangesList}
+		case method == http.MethodPost && p == "channels/stop":
+			return Request{Method: DriveChannelsStop}
+		}
+		// files/{fileId}/watch
+		segments := strings.Split(p, "/")
+		if method == http.MethodPost && len(segments) == 3 && segments[0] == "files" && segments[2] == "watch" {
+			fileId, err := url.PathUnescape(segments[1])
+			if err != nil {
+				return Request{}
+			}
+			return Request{Method: DriveFilesWatch, FileId: fileId}
+		}
+	case strings.HasPrefix(path, calendarPath):
+		p := strings.TrimPrefix(path, calendarPath)
+		if method == http.MethodPost && p == "channels/stop" {
+			return Request{Method: CalendarChannelsStop}
+		}
+		// calendars/{calendarId}/events[/watch]
+		segments := strings.Split(p, "/")
+		if len(segments) < 3 || segments[0] != "calendars" || segments[2] != "events" {
+			return Request{}
+		}
+		calendarId, err := url.PathUnescape(segments[1])
+		if err != nil {
+			return Request{}
+		}
+		switch {
+		case method == http.MethodGet && len(segments) == 3:
+			return Request{Method: CalendarEventsList, CalendarId: calendarId}
+		case method == http.MethodPost && len(segments) == 4 && segments[3] == "watch":
+			return Request{Method: CalendarEventsWatch, CalendarId: calendarId}
+		}
+	case strings.HasPrefix(path, gmailPath):
+		switch p := strings.TrimPrefix(path, gmailPath); {
+		case method == http.MethodGet && p == "profile":
+			return Request{Method: GmailUsersGetProfile}
+		case method == http.MethodPost && p == "watch":
+			return Request{Method: GmailUsersWatch}
+		case method == http.MethodPost && p == "stop":
+			return Request{Method: GmailUsersStop}
+		case method == http.MethodGet && p == "history":
+			return Request{Method: GmailUsersHistoryList}
+		}
+	case strings.HasPrefix(path, adminPath):
+		p := strings.TrimPrefix(path, adminPath)
+		if method != http.MethodPost {
+			return Request{}
+		}
+		if p == "reports_v1/channels/stop" {
+			return Request{Method: ReportsChannelsStop}
+		}
+		// reports/v1/activity/users/{userKey}/applications/{applicationName}/watch
+		segments := strings.Split(p, "/")
+		if len(segments) != 8 || segments[0] != "reports" || segments[2] != "activity" || segments[3] != "users" ||
+			segments[5] != "applications" || segments[7] != "watch" {
+			return Request{}
+		}
+		userKey, err := url.PathUnescape(segments[4])
+		if err != nil {
+			return Request{}
+		}
+		application, err := url.PathUnescape(segments[6])
+		if err != nil {
+			return Request{}
+		}
+		return Request{Method: ReportsActivitiesWatch, UserKey: userKey, Application: application}
+	case strings.HasPrefix(path, pubsubPath):
+		// projects/{project}/subscriptions[/{subscription}[:modifyPushConfig]]
+		p := strings.TrimPrefix(path, pubsubPath)
+		segments := strings.Split(p, "/")
+		if len(segments) < 3 || segments[0] != "projects" || segments[2] != "subscriptions" {
+			return Request{}
+		}
+		if len(segments) == 3 {
+			if method == http.MethodGet {
+				return Request{Method: PubsubSubscriptionsList, Subscription: segments[1]}
+			}
+			return Request{}
+		}
+		if len(segments) != 4 {
+			return Request{}
+		}
+		if strings.HasSuffix(p, ":modifyPushConfig") {
+			if method == http.MethodPost {
+				return Request{Method: PubsubModifyPushConfig, Subscription: strings.TrimSuffix(p, ":modifyPushConfig")}
+			}
+			return Request{}
+		}
+		switch method {
+		case http.MethodPut:
+			return Request{Method: PubsubSubscriptionsCreate, Subscription: p}
+		case http.MethodGet:
+			return Request{Method: PubsubSubscriptionsGet, Subscription: p}
+		case http.MethodDelete:
+			return Request{Method: PubsubSubscriptionsDelete, Subscription: p}
+		}
+	}
+	return Request{}
+}
+
+// defaultResponse returns the response of a request that was not scripted. It must be called with mu held.
+func (f *GoogleAPI) defaultResponse(req Request) Response {
+	switch req.Method {
+	case DriveChangesGetStartPageToken:
+		return Response{Body: &gsdrive.StartPageToken{StartPageToken: StartPageToken}}
+	case DriveChangesList:
+		return Response{Body: &gsdrive.ChangeList{NewStartPageToken: req.Query.Get("pageToken")}}
+	case CalendarEventsList:
+		syncToken := req.Query.Get("syncToken")
+		if syncToken == "" {
+			syncToken = SyncToken
+		}
+		return Response{Body: map[string]interface{}{"items": []interface{}{}, "nextSyncToken": syncToken}}
+	case DriveChangesWatch, DriveFilesWatch, CalendarEventsWatch, ReportsActivitiesWatch:
+		return f.watch(req)
+	case DriveChannelsStop, CalendarChannelsStop, ReportsChannelsStop:
+		var channel gsdrive.Channel
+		if err := json.Unmarshal(req.Body, &channel); err != nil {
+			return ErrorResponse(http.StatusBadRequest, err.Error())
+		}
+		if _, ok := f.channels[channel.Id]; !ok {
+			return ErrorResponse(http.StatusNotFound, fmt.Sprintf("Channel '%s' not found", channel.Id))
+		}
+		delete(f.channels, channel.Id)
+		return Response{Code: http.StatusNoContent}
+	case GmailUsersGetProfile:
+		return Response{Body: map[string]interface{}{"historyId": HistoryId}}
+	case GmailUsersWatch:
+		expiration := time.Now().Add(DefaultChannelExpiration).UnixNano() / int64(time.Millisecond)
+		return Response{Body: map[string]interface{}{"historyId": HistoryId, "expiration": strconv.FormatInt(expiration, 10)}}
+	case GmailUsersStop:
+		return Response{Code: http.StatusNoContent}
+	case GmailUsersHistoryList:
+		return Response{Body: map[string]interface{}{"historyId": req.Query.Get("startHistoryId")}}
+	case PubsubSubscriptionsCreate, PubsubSubscriptionsGet, PubsubSubscriptionsDelete, PubsubSubscriptionsList, PubsubModifyPushConfig:
+		return f.subscription(req)
+	}
+	return ErrorResponse(http.StatusNotImplemented, req.Method)
+}
+
+// pubsubSubscription is the JSON representation of a Pub/Sub subscription.
+type pubsubSubscription struct {
+	Name       string `json:"name,omitempty"`
+	Topic      string `json:"topic,omitempty"`
+	PushConfig struct {
+		PushEndpoint string `json:"pushEndpoint"`
+	} `json:"pushConfig"`
+}
+
+// subscription serves the Pub/Sub subscriptions calls. It must be called with mu held.
+func (f *GoogleAPI) subscription(req Request) Response {
+	var body pubsubSubscription
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return ErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	}
+	if req.Method == PubsubSubscriptionsList {
+		prefix := "projects/" + req.Subscription + "/subscriptions/"
+		subscriptions := []pubsubSubscription{}
+		for name, s := range f.subscriptions {
+			if strings.HasPrefix(name, prefix) {
+				subscriptions = append(subscriptions, toPubsub(s))
+			}
+		}
+		sort.Slice(subscriptions, func(i, j int) bool {
+			return subscriptions[i].Name < subscriptions[j].Name
+		})
+		return Response{Body: map[string]interface{}{"subscriptions": subscriptions}}
+	}
+
+	s, ok := f.subscriptions[req.Subscription]
+	if req.Method == PubsubSubscriptionsCreate {
+		if ok {
+			return ErrorResponse(http.StatusConflict, "Resource already exists in the project")
+		}
+		if body.Topic == "" {
+			return ErrorResponse(http.StatusBadRequest, "missing topic")
+		}
+		s = &Subscription{Name: req.Subscription, Topic: body.Topic, PushEndpoint: body.PushConfig.PushEndpoint}
+		f.subscriptions[s.Name] = s
+		return Response{Body: toPubsub(s)}
+	}
+	if !ok {
+		return ErrorResponse(http.StatusNotFound, "Resource not found")
+	}
+	switch req.Method {
+	case PubsubSubscriptionsDelete:
+		delete(f.subscriptions, s.Name)
+		return Response{Body: map[string]interface{}{}}
+	case PubsubModifyPushConfig:
+		s.PushEndpoint = body.PushConfig.PushEndpoint
+		return Response{Body: map[string]interface{}{}}
+	}
+	return Response{Body: toPubsub(s)}
+}
+
+func toPubsub(s *Subscription) pubsubSubscription {
+	p := pubsubSubscription{Name: s.Name, Topic: s.Topic}
+	p.PushConfig.PushEndpoint = s.PushEndpoint
+	return p
+}
+
+// watch creates a channel with a new resource ID. It must be called with mu held.
+func (f *GoogleAPI) watch(req Request) Response {
+	var channel gsdrive.Channel
+	if err := json.Unmarshal(req.Body, &channel); err != nil {
+		return ErrorResponse(http.StatusBadRequest, err.Error())
+	}
+	if channel.Id == "" || channel.Address == "" {
+		return ErrorResponse(http.StatusBadRequest, "missing channel id or address")
+	}
+	f.resources++
+	channel.ResourceId = fmt.Sprintf("resource-%d", f.resources)
+	channel.ResourceUri = f.server.URL + drivePath + "changes"
+	if req.CalendarId != "" {
+		channel.ResourceUri = f.server.URL + calendarPath + "calendars/" + url.PathEscape(req.CalendarId) + "/events"
+	}
+	if req.FileId != "" {
+		channel.ResourceUri = f.server.URL + drivePath + "files/" + url.PathEscape(req.FileId)
+	}
+	if req.Application != "" {
+		channel.ResourceUri = f.server.URL + adminPath + "reports/v1/activity/users/" + url.PathEscape(req.UserKey) +
+			"/applications/" + url.PathEscape(req.Application)
+	}
+	expiration := time.Now().Add(DefaultChannelExpiration)
+	if channel.Expiration > 0 {
+		expiration = time.Unix(0, channel.Expiration*int64(time.Millisecond))
+	}
+	channel.Expiration = expiration.UnixNano() / int64(time.Millisecond)
+	f.channels[channel.Id] = &Channel{
+		Id:          channel.Id,
+		ResourceId:  channel.ResourceId,
+		ResourceURI: channel.ResourceUri,
+		Token:       channel.Token,
+		Address:     channel.Address,
+		Expiration:  expiration,
+		CalendarId:  req.CalendarId,
+		FileId:      req.FileId,
+		Application: req.Application,
+	}
+	return Response{Body: &channel}
+}
+
+func writeJSON(w http.ResponseWriter, resp Response) {
+	code := resp.Code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	if resp.Body == nil {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp.Body)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gsdrive "google.golang.org/api/drive/v3"
+)
+
+func TestNotifyChannel(t *testing.T) {
+	api := NewGoogleAPI()
+	defer api.Close()
+
+	var got []*http.Request
+	adapter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r)
+	}))
+	defer adapter.Close()
+
+	svc, err := gsdrive.NewService(context.Background(), api.DriveOptions()...)
+	if err != nil {
+		t.Fatalf("NewService() = %v", err)
+	}
+	channel, err := svc.Changes.Watch(StartPageToken, &gsdrive.Channel{
+		Id:      "channel-1",
+		Token:   "secret",
+		Address: adapter.URL + "/user/",
+	}).Do()
+	if err != nil {
+		t.Fatalf("changes.watch = %v", err)
+	}
+
+	for _, state := range []string{"sync", "change"} {
+		resp, err := api.NotifyChannel(channel.Id, state)
+		if err != nil {
+			t.Fatalf("NotifyChannel() = %v", err)
+		}
+		resp.Body.Close()
+	}
+	if len(got) != 2 {
+		t.Fatalf("adapter got %d notifications, want 2", len(got))
+	}
+	last := got[1]
+	for header, want := range map[string]string{
+		"X-Goog-Channel-ID":     "channel-1",
+		"X-Goog-Channel-Token":  "secret",
+		"X-Goog-Resource-ID":    channel.ResourceId,
+		"X-Goog-Resource-State": "change",
+		"X-Goog-Message-Number": "2",
+	} {
+		if v := last.Header.Get(header); v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+	if last.URL.Path != "/user/" {
+		t.Errorf("path = %q, want %q", last.URL.Path, "/user/")
+	}
+}
+
+func TestScriptedError(t *testing.T) {
+	api := NewGoogleAPI()
+	defer api.Close()
+	api.Script(DriveChannelsStop, ErrorResponse(http.StatusNotFound, "not found"))
+
+	svc, err := gsdrive.NewService(context.Background(), api.DriveOptions()...)
+	if err != nil {
+		t.Fatalf("NewService() = %v", err)
+	}
+	if err := svc.Channels.Stop(&gsdrive.Channel{Id: "channel-1"}).Do(); err == nil {
+		t.Error("channels.stop succeeded, want the scripted error")
+	}
+	if got := len(api.Requests(DriveChannelsStop)); got != 1 {
+		t.Errorf("got %d channels.stop requests, want 1", got)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// SinkRequest is a request received by Sink, i.e., a CloudEvent sent over HTTP.
+type SinkRequest struct {
+	// Time is when the request was received.
+	Time   time.Time
+	Header http.Header
+	Body   []byte
+}
+
+// Sink is an in-process fake of an event sink. By default, it accepts all the events, and the status codes
+// of its responses can be scripted, e.g., to fail the deliveries.
+type Sink struct {
+	server *httptest.Server
+
+	// mu guards the fields below, as the fake serves concurrent requests.
+	mu       sync.Mutex
+	codes    []int
+	requests []SinkRequest
+}
+
+// NewSink starts a fake sink. It must be closed when done.
+func NewSink() *Sink {
+	s := &Sink{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts the server down.
+func (s *Sink) Close() {
+	s.server.Close()
+}
+
+// URL returns the URL of the sink.
+func (s *Sink) URL() string {
+	return s.server.URL
+}
+
+// Script queues the status codes of the next responses, which are returned in order, before the default 202.
+func (s *Sink) Script(codes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes = append(s.codes, codes...)
+}
+
+// Requests returns the requests received, in order.
+func (s *Sink) Requests() []SinkRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SinkRequest(nil), s.requests...)
+}
+
+func (s *Sink) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, SinkRequest{Time: time.Now(), Header: r.Header, Body: body})
+	code := http.StatusAccepted
+	if len(s.codes) > 0 {
+		code, s.codes = s.codes[0], s.codes[1:]
+	}
+	w.WriteHeader(code)
+}
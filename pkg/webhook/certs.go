@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	organization = "nachocano.org"
+	// certValidity is how long the generated certificates are valid for.
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+// CreateCerts creates a CA and a server certificate signed by it, valid for the DNS names of the given service.
+// It returns the PEM encoded server key, server certificate and CA certificate.
+func CreateCerts(serviceName, namespace string) (serverKey, serverCert, caCert []byte, err error) {
+	caKey, caCertificate, caCertPEM, err := createCA(serviceName, namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate server key: %v", err)
+	}
+	tmpl, err := createServerCertTemplate(serviceName, namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	_, serverCertPEM, err := createCert(tmpl, caCertificate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	serverKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return serverKeyPEM, serverCertPEM, caCertPEM, nil
+}
+
+func createCA(serviceName, namespace string) (*rsa.PrivateKey, *x509.Certificate, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate CA key: %v", err)
+	}
+	tmpl, err := createCertTemplate(serviceName, namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	cert, certPEM, err := createCert(tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return key, cert, certPEM, nil
+}
+
+func createServerCertTemplate(serviceName, namespace string) (*x509.Certificate, error) {
+	tmpl, err := createCertTemplate(serviceName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	return tmpl, nil
+}
+
+func createCertTemplate(serviceName, namespace string) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %v", err)
+	}
+	name := serviceName + "." + namespace
+	return &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{organization}, CommonName: name + ".svc"},
+		SignatureAlgorithm:    x509.SHA256WithRSA,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(certValidity),
+		BasicConstraintsValid: true,
+		DNSNames:              []string{serviceName, name, name + ".svc", name + ".svc.cluster.local"},
+	}, nil
+}
+
+func createCert(tmpl, parent *x509.Certificate, pub *rsa.PublicKey, parentKey *rsa.PrivateKey) (*x509.Certificate, []byte, error) {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	var certPEM bytes.Buffer
+	if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode certificate: %v", err)
+	}
+	return cert, certPEM.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	fakecorev1 "k8s.io/client-go/kubernetes/typed/core/v1/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCreateCerts(t *testing.T) {
+	serverKey, serverCert, caCert, err := CreateCerts("webhook", "gsuite-sources")
+	if err != nil {
+		t.Fatalf("CreateCerts() = %v", err)
+	}
+	if _, err := tls.X509KeyPair(serverCert, serverKey); err != nil {
+		t.Errorf("server key pair = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caCert) {
+		t.Fatal("failed to parse the CA certificate")
+	}
+	block, _ := pem.Decode(serverCert)
+	if block == nil {
+		t.Fatal("failed to decode the server certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse the server certificate: %v", err)
+	}
+	for _, name := range []string{
+		"webhook",
+		"webhook.gsuite-sources",
+		"webhook.gsuite-sources.svc",
+		"webhook.gsuite-sources.svc.cluster.local",
+	} {
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("server certificate not valid for %s: %v", name, err)
+		}
+	}
+}
+
+// fakeClientset is a kubernetes.Interface whose core client is backed by an object tracker.
+type fakeClientset struct {
+	kubernetes.Interface
+	fake *clienttesting.Fake
+}
+
+func newFakeClientset(objects ...runtime.Object) *fakeClientset {
+	tracker := clienttesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	fake := &clienttesting.Fake{}
+	fake.AddReactor("*", "*", clienttesting.ObjectReaction(tracker))
+	return &fakeClientset{fake: fake}
+}
+
+func (c *fakeClientset) CoreV1() corev1client.CoreV1Interface {
+	return &fakecorev1.FakeCoreV1{Fake: c.fake}
+}
+
+func TestCerts(t *testing.T) {
+	client := newFakeClientset()
+	ac := &AdmissionController{
+		Client: client,
+		Options: Options{
+			ServiceName: "webhook",
+			Namespace:   "gsuite-sources",
+			SecretName:  "webhook-certs",
+		},
+		Logger: zap.NewNop().Sugar(),
+	}
+
+	serverKey, serverCert, caCert, err := ac.certs(context.Background())
+	if err != nil {
+		t.Fatalf("certs() = %v", err)
+	}
+	if _, err := tls.X509KeyPair(serverCert, serverKey); err != nil {
+		t.Errorf("server key pair = %v", err)
+	}
+	secret, err := client.CoreV1().Secrets("gsuite-sources").Get("webhook-certs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the certificates secret: %v", err)
+	}
+	if string(secret.Data[secretCACert]) != string(caCert) {
+		t.Error("secret CA certificate differs from the returned one")
+	}
+
+	// The second time, the certificates of the secret are used.
+	_, _, caCert2, err := ac.certs(context.Background())
+	if err != nil {
+		t.Fatalf("certs() = %v", err)
+	}
+	if string(caCert2) != string(caCert) {
+		t.Error("certs() generated new certificates, want the ones of the secret")
+	}
+	creates := 0
+	for _, action := range client.fake.Actions() {
+		if action.GetVerb() == "create" {
+			creates++
+		}
+	}
+	if creates != 1 {
+		t.Errorf("secret created %d times, want 1", creates)
+	}
+}
+
+func TestCertsIncompleteSecret(t *testing.T) {
+	ac := &AdmissionController{
+		Client: newFakeClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "gsuite-sources", Name: "webhook-certs"},
+			Data:       map[string][]byte{secretServerKey: []byte("key")},
+		}),
+		Options: Options{
+			ServiceName: "webhook",
+			Namespace:   "gsuite-sources",
+			SecretName:  "webhook-certs",
+		},
+		Logger: zap.NewNop().Sugar(),
+	}
+	if _, _, _, err := ac.certs(context.Background()); err == nil {
+		t.Error("certs() = nil, want an error about the missing keys")
+	}
+}
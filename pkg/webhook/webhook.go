@@ -0,0 +1,330 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook implements the admission webhook that defaults and validates the G Suite sources.
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/knative/pkg/apis"
+	"github.com/knative/pkg/logging"
+	"github.com/mattbaird/jsonpatch"
+	"go.uber.org/zap"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Keys of the secret holding the webhook certificates.
+	secretServerKey  = "server-key.pem"
+	secretServerCert = "server-cert.pem"
+	secretCACert     = "ca-cert.pem"
+)
+
+// GenericCRD is the interface implemented by the types defaulted and validated by the webhook.
+type GenericCRD interface {
+	apis.Defaultable
+	apis.Validatable
+	runtime.Object
+	metav1.Object
+}
+
+// Options are the options of the admission webhook.
+type Options struct {
+	// ServiceName is the name of the Kubernetes Service the API server reaches the webhook through.
+	ServiceName string
+	// Namespace is the namespace of the Service and of the certificates secret.
+	Namespace string
+	// Port is the port the webhook listens on.
+	Port int
+	// SecretName is the name of the secret holding the webhook certificates.
+	// The certificates are generated the first time the webhook starts.
+	SecretName string
+	// WebhookName is the name of the MutatingWebhookConfiguration registered with the API server.
+	WebhookName string
+}
+
+// AdmissionController defaults and validates the objects of the kinds in Handlers.
+// It implements manager.Runnable, so that it runs along with the controllers.
+type AdmissionController struct {
+	Client   kubernetes.Interface
+	Options  Options
+	Handlers map[schema.GroupVersionKind]GenericCRD
+	Logger   *zap.SugaredLogger
+}
+
+// Start gets or generates the webhook certificates, registers the webhook and serves the admission
+// requests until the stop channel is closed.
+func (ac *AdmissionController) Start(stop <-chan struct{}) error {
+	ctx := logging.WithLogger(context.Background(), ac.Logger)
+
+	serverKey, serverCert, caCert, err := ac.certs(ctx)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		return fmt.Errorf("failed to load the webhook certificates: %v", err)
+	}
+	if err := ac.register(ctx, caCert); err != nil {
+		return fmt.Errorf("failed to register the webhook: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:      fmt.Sprintf(":%d", ac.Options.Port),
+		Handler:   ac,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			errCh <- fmt.Errorf("webhook server failed: %v", err)
+		}
+	}()
+
+	ac.Logger.Infof("Webhook listening on port %d", ac.Options.Port)
+	select {
+	case <-stop:
+		return server.Close()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// certs returns the webhook certificates from the secret, creating them if the secret does not exist.
+func (ac *AdmissionController) certs(ctx context.Context) (serverKey, serverCert, caCert []byte, err error) {
+	secrets := ac.Client.CoreV1().Secrets(ac.Options.Namespace)
+	secret, err := secrets.Get(ac.Options.SecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		serverKey, serverCert, caCert, err = CreateCerts(ac.Options.ServiceName, ac.Options.Namespace)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ac.Options.SecretName,
+				Namespace: ac.Options.Namespace,
+			},
+			Data: map[string][]byte{
+				secretServerKey:  serverKey,
+				secretServerCert: serverCert,
+				secretCACert:     caCert,
+			},
+		}
+		secret, err = secrets.Create(secret)
+		if apierrors.IsAlreadyExists(err) {
+			// Another replica created it first, use its certificates.
+			secret, err = secrets.Get(ac.Options.SecretName, metav1.GetOptions{})
+		}
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the webhook certificates secret: %v", err)
+	}
+
+	for _, key := range []string{secretServerKey, secretServerCert, secretCACert} {
+		if len(secret.Data[key]) == 0 {
+			return nil, nil, nil, fmt.Errorf("webhook certificates secret %q is missing %q", ac.Options.SecretName, key)
+		}
+	}
+	return secret.Data[secretServerKey], secret.Data[secretServerCert], secret.Data[secretCACert], nil
+}
+
+// register creates or updates the MutatingWebhookConfiguration that sends the creations and updates
+// of the handled kinds to the webhook.
+func (ac *AdmissionController) register(ctx context.Context, caCert []byte) error {
+	var rules []admissionregistrationv1beta1.RuleWithOperations
+	for gvk := range ac.Handlers {
+		rules = append(rules, admissionregistrationv1beta1.RuleWithOperations{
+			Operations: []admissionregistrationv1beta1.OperationType{
+				admissionregistrationv1beta1.Create,
+				admissionregistrationv1beta1.Update,
+			},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{gvk.Group},
+				APIVersions: []string{gvk.Version},
+				Resources:   []string{strings.ToLower(gvk.Kind) + "s"},
+			},
+		})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Resources[0] < rules[j].Resources[0]
+	})
+
+	path := "/"
+	failurePolicy := admissionregistrationv1beta1.Fail
+	webhooks := []admissionregistrationv1beta1.Webhook{{
+		Name:  ac.Options.WebhookName,
+		Rules: rules,
+		ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+			Service: &admissionregistrationv1beta1.ServiceReference{
+				Namespace: ac.Options.Namespace,
+				Name:      ac.Options.ServiceName,
+				Path:      &path,
+			},
+			CABundle: caCert,
+		},
+		FailurePolicy: &failurePolicy,
+	}}
+
+	client := ac.Client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := client.Get(ac.Options.WebhookName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(&admissionregistrationv1beta1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: ac.Options.WebhookName},
+			Webhooks:   webhooks,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing.Webhooks = webhooks
+	_, err = client.Update(existing)
+	return err
+}
+
+// ServeHTTP serves the admission reviews sent by the API server.
+func (ac *AdmissionController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var review admissionv1beta1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "missing admission request", http.StatusBadRequest)
+		return
+	}
+
+	request := review.Request
+	logger := ac.Logger.With(
+		zap.String("kind", request.Kind.Kind),
+		zap.String("namespace", request.Namespace),
+		zap.String("name", request.Name),
+		zap.String("operation", string(request.Operation)))
+	ctx := logging.WithLogger(r.Context(), logger)
+
+	response := ac.admit(ctx, request)
+	response.UID = request.UID
+	if err := json.NewEncoder(w).Encode(admissionv1beta1.AdmissionReview{Response: response}); err != nil {
+		logger.Errorf("Failed to encode the admission response: %v", err)
+	}
+}
+
+// admit defaults and validates the object of the request.
+func (ac *AdmissionController) admit(ctx context.Context, request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	logger := logging.FromContext(ctx)
+	switch request.Operation {
+	case admissionv1beta1.Create, admissionv1beta1.Update:
+	default:
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	patch, err := ac.mutate(ctx, request)
+	if err != nil {
+		logger.Infof("Admission rejected: %v", err)
+		return &admissionv1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			},
+		}
+	}
+	if len(patch) == 0 {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// mutate sets the defaults of the object of the request and validates it. On updates, it also checks
+// that its immutable fields did not change. It returns the JSON patch with the defaults, if any.
+func (ac *AdmissionController) mutate(ctx context.Context, request *admissionv1beta1.AdmissionRequest) ([]byte, error) {
+	gvk := schema.GroupVersionKind{
+		Group:   request.Kind.Group,
+		Version: request.Kind.Version,
+		Kind:    request.Kind.Kind,
+	}
+	handler, ok := ac.Handlers[gvk]
+	if !ok {
+		return nil, fmt.Errorf("unhandled kind: %v", gvk)
+	}
+
+	newObj, err := decode(handler, request.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode object: %v", err)
+	}
+	// Objects being deleted are not validated, so that their finalizers can always be removed.
+	if newObj.GetDeletionTimestamp() != nil {
+		return nil, nil
+	}
+	if request.Operation == admissionv1beta1.Update {
+		oldObj, err := decode(handler, request.OldObject.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode old object: %v", err)
+		}
+		ctx = apis.WithinUpdate(ctx, oldObj)
+	} else {
+		ctx = apis.WithinCreate(ctx)
+	}
+
+	before, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+	newObj.SetDefaults(ctx)
+	if fe := newObj.Validate(ctx); fe != nil {
+		return nil, fe
+	}
+	after, err := json.Marshal(newObj)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := jsonpatch.CreatePatch(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(ops)
+}
+
+// decode decodes the given raw object into a new object of the type of the handler.
+func decode(handler GenericCRD, raw []byte) (GenericCRD, error) {
+	obj := handler.DeepCopyObject().(GenericCRD)
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
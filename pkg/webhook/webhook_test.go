@@ -0,0 +1,221 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mattbaird/jsonpatch"
+	"go.uber.org/zap"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/nachocano/gsuite-source/pkg/apis/sources/v1alpha1"
+)
+
+func newAdmissionController() *AdmissionController {
+	return &AdmissionController{
+		Handlers: map[schema.GroupVersionKind]GenericCRD{
+			v1alpha1.SchemeGroupVersion.WithKind("DriveSource"): &v1alpha1.DriveSource{},
+		},
+		Logger: zap.NewNop().Sugar(),
+	}
+}
+
+func newDriveSource() *v1alpha1.DriveSource {
+	return &v1alpha1.DriveSource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "DriveSource",
+		},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "drive-source"},
+		Spec: v1alpha1.DriveSourceSpec{
+			GSuiteSourceSpec: v1alpha1.GSuiteSourceSpec{
+				EmailAddress: "user@example.com",
+				GcpCredsSecret: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "gcp-creds"},
+					Key:                  "key.json",
+				},
+				Sink: &corev1.ObjectReference{
+					APIVersion: "eventing.knative.dev/v1alpha1",
+					Kind:       "Channel",
+					Name:       "sink",
+				},
+			},
+		},
+	}
+}
+
+// review sends an admission review of the given objects to the controller and returns its response.
+func review(t *testing.T, ac *AdmissionController, operation admissionv1beta1.Operation, obj, oldObj runtime.Object) *admissionv1beta1.AdmissionResponse {
+	t.Helper()
+	request := &admissionv1beta1.AdmissionRequest{
+		UID:       "uid",
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.SchemeGroupVersion.Group, Version: v1alpha1.SchemeGroupVersion.Version, Kind: "DriveSource"},
+		Namespace: "default",
+		Name:      "drive-source",
+		Operation: operation,
+	}
+	var err error
+	if request.Object.Raw, err = json.Marshal(obj); err != nil {
+		t.Fatal(err)
+	}
+	if oldObj != nil {
+		if request.OldObject.Raw, err = json.Marshal(oldObj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	body, err := json.Marshal(admissionv1beta1.AdmissionReview{Request: request})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	ac.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var got admissionv1beta1.AdmissionReview
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode the admission review: %v", err)
+	}
+	if got.Response == nil {
+		t.Fatal("ServeHTTP() returned no response")
+	}
+	if got.Response.UID != request.UID {
+		t.Errorf("response UID = %q, want %q", got.Response.UID, request.UID)
+	}
+	return got.Response
+}
+
+func TestServeHTTPDefaults(t *testing.T) {
+	response := review(t, newAdmissionController(), admissionv1beta1.Create, newDriveSource(), nil)
+	if !response.Allowed {
+		t.Fatalf("response not allowed: %v", response.Result)
+	}
+	if response.PatchType == nil || *response.PatchType != admissionv1beta1.PatchTypeJSONPatch {
+		t.Errorf("response patch type = %v, want %v", response.PatchType, admissionv1beta1.PatchTypeJSONPatch)
+	}
+
+	var ops []jsonpatch.JsonPatchOperation
+	if err := json.Unmarshal(response.Patch, &ops); err != nil {
+		t.Fatalf("failed to decode the patch %s: %v", response.Patch, err)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].Path < ops[j].Path
+	})
+	want := []jsonpatch.JsonPatchOperation{{
+		Operation: "add",
+		Path:      "/spec/cloudEvents",
+		Value: map[string]interface{}{
+			"specVersion": v1alpha1.DefaultCloudEventsSpecVersion,
+			"mode":        string(v1alpha1.CloudEventsModeBinary),
+		},
+	}, {
+		Operation: "add",
+		Path:      "/spec/delivery",
+		Value: map[string]interface{}{
+			"retries":      float64(v1alpha1.DefaultDeliveryRetries),
+			"backoffDelay": v1alpha1.DefaultDeliveryBackoffDelay.String(),
+		},
+	}}
+	if diff := cmp.Diff(want, ops); diff != "" {
+		t.Errorf("patch (-want, +got) = %v", diff)
+	}
+}
+
+func TestServeHTTPNoPatch(t *testing.T) {
+	ac := newAdmissionController()
+	source := newDriveSource()
+	source.SetDefaults(context.Background())
+	response := review(t, ac, admissionv1beta1.Create, source, nil)
+	if !response.Allowed {
+		t.Fatalf("response not allowed: %v", response.Result)
+	}
+	if len(response.Patch) != 0 || response.PatchType != nil {
+		t.Errorf("response patch = %s, want none", response.Patch)
+	}
+}
+
+func TestServeHTTPRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		operation   admissionv1beta1.Operation
+		mutate      func(s *v1alpha1.DriveSource)
+		wantMessage string
+	}{{
+		name:      "invalid email",
+		operation: admissionv1beta1.Create,
+		mutate: func(s *v1alpha1.DriveSource) {
+			s.Spec.EmailAddress = "not an email"
+		},
+		wantMessage: "spec.emailAddress",
+	}, {
+		name:      "sink without apiVersion",
+		operation: admissionv1beta1.Create,
+		mutate: func(s *v1alpha1.DriveSource) {
+			s.Spec.Sink.APIVersion = ""
+		},
+		wantMessage: "spec.sink.apiVersion",
+	}, {
+		name:      "changed gcpCredsSecret",
+		operation: admissionv1beta1.Update,
+		mutate: func(s *v1alpha1.DriveSource) {
+			s.Spec.GcpCredsSecret.Name = "other-creds"
+		},
+		wantMessage: "spec.gcpCredsSecret",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var oldObj runtime.Object
+			if tc.operation == admissionv1beta1.Update {
+				oldObj = newDriveSource()
+			}
+			source := newDriveSource()
+			tc.mutate(source)
+			response := review(t, newAdmissionController(), tc.operation, source, oldObj)
+			if response.Allowed {
+				t.Fatal("response allowed, want rejected")
+			}
+			if response.Result == nil || !strings.Contains(response.Result.Message, tc.wantMessage) {
+				t.Errorf("response result = %v, want a message about %s", response.Result, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestServeHTTPDeleting(t *testing.T) {
+	source := newDriveSource()
+	source.Spec.EmailAddress = "not an email"
+	now := metav1.Now()
+	source.DeletionTimestamp = &now
+	response := review(t, newAdmissionController(), admissionv1beta1.Update, source, newDriveSource())
+	if !response.Allowed {
+		t.Errorf("response not allowed: %v", response.Result)
+	}
+}